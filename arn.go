@@ -0,0 +1,152 @@
+package invoker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+var (
+	fullARN    = regexp.MustCompile(`^arn:(aws[a-zA-Z-]*):lambda:([a-z]{2}(?:-gov)?-[a-z]+-\d):(\d{12}):function:([a-zA-Z0-9-_]{1,64})(?::(\$LATEST|[a-zA-Z0-9-_]+))?$`)
+	partialARN = regexp.MustCompile(`^(\d{12}):function:([a-zA-Z0-9-_]{1,64})(?::(\$LATEST|[a-zA-Z0-9-_]+))?$`)
+	bareName   = regexp.MustCompile(`^([a-zA-Z0-9-_]{1,64})(?::(\$LATEST|[a-zA-Z0-9-_]+))?$`)
+)
+
+// FunctionARN identifies a lambda function. Depending on how the function was
+// named some of the fields may be empty; only Name is always set.
+type FunctionARN struct {
+	Partition string
+	Region    string
+	AccountID string
+	Name      string
+	Qualifier string
+}
+
+// String formats the function as the most qualified name it can; a full ARN
+// if the partition, region and account are known, a partial ARN if only the
+// account is known, or otherwise just its name.
+func (f FunctionARN) String() string {
+	var s string
+	switch {
+	case f.Partition != "" && f.Region != "" && f.AccountID != "":
+		s = fmt.Sprintf("arn:%s:lambda:%s:%s:function:%s", f.Partition, f.Region, f.AccountID, f.Name)
+	case f.AccountID != "":
+		s = fmt.Sprintf("%s:function:%s", f.AccountID, f.Name)
+	default:
+		s = f.Name
+	}
+	if f.Qualifier != "" {
+		s += ":" + f.Qualifier
+	}
+	return s
+}
+
+// ParseFunctionARN parses s as either a function name, a partial ARN
+// ('account:function:name') or a full ARN
+// ('arn:aws:lambda:region:account:function:name'). Each may be suffixed with a
+// ':qualifier'.
+func ParseFunctionARN(s string) (FunctionARN, error) {
+	if m := fullARN.FindStringSubmatch(s); m != nil {
+		return FunctionARN{
+			Partition: m[1],
+			Region:    m[2],
+			AccountID: m[3],
+			Name:      m[4],
+			Qualifier: m[5],
+		}, nil
+	}
+	if m := partialARN.FindStringSubmatch(s); m != nil {
+		return FunctionARN{
+			AccountID: m[1],
+			Name:      m[2],
+			Qualifier: m[3],
+		}, nil
+	}
+	if m := bareName.FindStringSubmatch(s); m != nil {
+		return FunctionARN{
+			Name:      m[1],
+			Qualifier: m[2],
+		}, nil
+	}
+	return FunctionARN{}, fmt.Errorf("'%s' isn't a valid function name or arn", s)
+}
+
+// NewValidated initializes an Invoker with the options passed, like New, but
+// returns an error if li is nil, arn isn't a valid function name or ARN, or if
+// the options aren't compatible with each other.
+func NewValidated(li LambdaInvoker, arn string, opts ...Option) (*Invoker, error) {
+	invoker := New(li, arn, opts...)
+	if invoker.err != nil {
+		return nil, invoker.err
+	}
+	function, err := ParseFunctionARN(arn)
+	if err != nil {
+		return nil, err
+	}
+	invoker.function = function
+	return invoker, nil
+}
+
+// Function returns the parsed identity of the function being invoked. It's
+// only populated for Invokers initialized with NewValidated.
+func (i *Invoker) Function() FunctionARN {
+	return i.function
+}
+
+// WithCrossAccount returns an option which can be passed when initializing an
+// Invoker. If provided the function will be invoked in the account and region
+// passed; the FunctionName is rewritten to a full ARN, keeping the function's
+// name and any qualifier. Without it the SDK resolves a function name against
+// the account and region of the caller's credentials.
+func WithCrossAccount(accountID, region string) Option {
+	return func(i *Invoker) {
+		i.crossAccount = &FunctionARN{
+			Partition: partition(region),
+			Region:    region,
+			AccountID: accountID,
+		}
+		i.inputMutators = append(i.inputMutators, func(_ context.Context, input *lambda.InvokeInput) error {
+			function, err := ParseFunctionARN(aws.StringValue(input.FunctionName))
+			if err != nil {
+				return err
+			}
+			target := *i.crossAccount
+			target.Name = function.Name
+			target.Qualifier = function.Qualifier
+			input.FunctionName = aws.String(target.String())
+			return nil
+		})
+	}
+}
+
+// validateCrossAccount checks the account and region passed to
+// WithCrossAccount, if any, aren't empty.
+func (i *Invoker) validateCrossAccount() error {
+	if i.crossAccount == nil {
+		return nil
+	}
+	if i.crossAccount.AccountID == "" {
+		return errors.New("cross account id can't be empty")
+	}
+	if i.crossAccount.Region == "" {
+		return errors.New("cross account region can't be empty")
+	}
+	return nil
+}
+
+// partition returns the aws partition region belongs to.
+func partition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	default:
+		return "aws"
+	}
+}
@@ -0,0 +1,160 @@
+package invoker
+
+import (
+	"context"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFunctionARN(t *testing.T) {
+	t.Parallel()
+	for name, tc := range map[string]struct {
+		arn      string
+		expected FunctionARN
+	}{
+		"name": {
+			arn:      "my-function",
+			expected: FunctionARN{Name: "my-function"},
+		},
+		"name with qualifier": {
+			arn:      "my-function:prod",
+			expected: FunctionARN{Name: "my-function", Qualifier: "prod"},
+		},
+		"partial arn": {
+			arn:      "123456789012:function:my-function",
+			expected: FunctionARN{AccountID: "123456789012", Name: "my-function"},
+		},
+		"full arn": {
+			arn: "arn:aws:lambda:eu-west-1:123456789012:function:my-function",
+			expected: FunctionARN{
+				Partition: "aws",
+				Region:    "eu-west-1",
+				AccountID: "123456789012",
+				Name:      "my-function",
+			},
+		},
+		"full arn with qualifier": {
+			arn: "arn:aws-us-gov:lambda:us-gov-west-1:123456789012:function:my-function:$LATEST",
+			expected: FunctionARN{
+				Partition: "aws-us-gov",
+				Region:    "us-gov-west-1",
+				AccountID: "123456789012",
+				Name:      "my-function",
+				Qualifier: "$LATEST",
+			},
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			function, err := ParseFunctionARN(tc.arn)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, function)
+		})
+	}
+}
+
+func TestParseFunctionARNInvalid(t *testing.T) {
+	t.Parallel()
+	for _, arn := range []string{
+		"",
+		"my function",
+		"arn:aws:lambda:eu-west-1:123:function:my-function",
+		"arn:aws:s3:::my-bucket",
+		"arn:aws:lambda:eu-west-1:123456789012:function:",
+	} {
+		_, err := ParseFunctionARN(arn)
+		assert.Error(t, err, arn)
+	}
+}
+
+func TestNewValidated(t *testing.T) {
+	t.Parallel()
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker, err := NewValidated(li, "arn:aws:lambda:eu-west-1:123456789012:function:my-function:prod")
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", invoker.Function().Region)
+	assert.Equal(t, "123456789012", invoker.Function().AccountID)
+	assert.Equal(t, "prod", invoker.Function().Qualifier)
+
+	_, err = NewValidated(li, "arn:aws:lambda:eu-west-1:function")
+	assert.Error(t, err)
+
+	_, err = NewValidated(li, "my-function", AsEvent(), AsProcedure("Do", nil))
+	assert.Error(t, err)
+}
+
+func TestNewValidatedNilInvoker(t *testing.T) {
+	t.Parallel()
+	_, err := NewValidated(nil, "my-function")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "lambda invoker")
+}
+
+func TestNewValidatedEmptyARN(t *testing.T) {
+	t.Parallel()
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	_, err := NewValidated(li, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "arn")
+}
+
+func TestInvokeWithNilInvoker(t *testing.T) {
+	t.Parallel()
+	_, err := New(nil, "my-function").Invoke(context.Background(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "lambda invoker")
+}
+
+func TestFunctionARNString(t *testing.T) {
+	t.Parallel()
+	for _, s := range []string{
+		"arn:aws:lambda:eu-west-1:123456789012:function:my-function",
+		"arn:aws:lambda:eu-west-1:123456789012:function:my-function:prod",
+		"123456789012:function:my-function",
+		"my-function:$LATEST",
+		"my-function",
+	} {
+		function, err := ParseFunctionARN(s)
+		require.NoError(t, err)
+		assert.Equal(t, s, function.String())
+	}
+}
+
+func TestInvokeWithCrossAccount(t *testing.T) {
+	t.Parallel()
+	var functionName string
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		functionName = *input.FunctionName
+		return &lambda.InvokeOutput{}, nil
+	})
+	_, err := New(li, "my-function", WithCrossAccount("123456789012", "eu-west-1")).Invoke(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:lambda:eu-west-1:123456789012:function:my-function", functionName)
+
+	_, err = New(li, "my-function:prod", WithCrossAccount("123456789012", "cn-north-1")).Invoke(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws-cn:lambda:cn-north-1:123456789012:function:my-function:prod", functionName)
+}
+
+func TestNewValidatedCrossAccountEmpty(t *testing.T) {
+	t.Parallel()
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	_, err := NewValidated(li, "my-function", WithCrossAccount("", "eu-west-1"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "account id")
+
+	_, err = NewValidated(li, "my-function", WithCrossAccount("123456789012", ""))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "region")
+}
@@ -0,0 +1,46 @@
+package invoker
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// STSClient abstracts the logic of assuming a role behind an interface, this
+// is to allow mocking the aws STS implementation.
+type STSClient interface {
+	AssumeRole(*sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error)
+}
+
+// assumeRoleExpiryWindow is how long before the temporary credentials expire
+// that they're refreshed.
+const assumeRoleExpiryWindow = time.Minute
+
+// WithAssumeRole returns an option which can be passed when initializing an
+// Invoker. If provided the lambda function will be invoked with temporary
+// credentials obtained by assuming the role passed, rather than the
+// credentials the LambdaInvoker was configured with. The credentials are
+// cached until shortly before they expire; it's safe to invoke concurrently.
+// Combine with WithCrossAccount to invoke a function in another account. The
+// credentials are passed to the LambdaInvoker as a request option, so it must
+// be a *lambda.Lambda, or report that it applies them by implementing
+// RequestOptionsReporter; otherwise Invoke returns an error rather than
+// invoking the function with the wrong credentials.
+func WithAssumeRole(roleARN string, client STSClient) Option {
+	return func(i *Invoker) {
+		if client == nil {
+			i.optionErr = errors.New("sts client can't be nil")
+			return
+		}
+		creds := stscreds.NewCredentialsWithClient(client, roleARN, func(p *stscreds.AssumeRoleProvider) {
+			p.ExpiryWindow = assumeRoleExpiryWindow
+		})
+		i.assumeRole = true
+		i.requestOptions = append(i.requestOptions, func(r *awsreq.Request) {
+			r.Config.Credentials = creds
+		})
+	}
+}
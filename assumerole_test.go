@@ -0,0 +1,90 @@
+package invoker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type STSClientFunc func(*sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error)
+
+func (f STSClientFunc) AssumeRole(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	return f(input)
+}
+
+func TestInvokeWithAssumeRole(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	roleARN := "arn:aws:iam::123456789012:role/invoker"
+	var assumed int32
+	client := STSClientFunc(func(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+		atomic.AddInt32(&assumed, 1)
+		assert.Equal(t, roleARN, *input.RoleArn)
+		return &sts.AssumeRoleOutput{
+			Credentials: &sts.Credentials{
+				AccessKeyId:     aws.String("access-key"),
+				SecretAccessKey: aws.String("secret"),
+				SessionToken:    aws.String("token"),
+				Expiration:      aws.Time(time.Now().Add(time.Hour)),
+			},
+		}, nil
+	})
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, opts ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		r := &awsreq.Request{}
+		for _, opt := range opts {
+			opt(r)
+		}
+		creds, err := r.Config.Credentials.Get()
+		if err != nil {
+			return nil, err
+		}
+		assert.Equal(t, "access-key", creds.AccessKeyID)
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithAssumeRole(roleARN, client))
+	wg := sync.WaitGroup{}
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := invoker.Invoke(ctx, nil)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&assumed))
+}
+
+func TestInvokeWithAssumeRoleNilClient(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	_, err := New(li, arn, WithAssumeRole("role", nil)).Invoke(ctx, nil)
+	require.Error(t, err)
+}
+
+func TestInvokeWithAssumeRoleRequestOptionsIgnored(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	client := STSClientFunc(func(*sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+		t.Fatal("role shouldn't be assumed")
+		return nil, nil
+	})
+	li := NewFunctionURLInvoker("https://example.lambda-url.us-east-1.on.aws/", nil)
+	_, err := New(li, arn, WithAssumeRole("role", client)).Invoke(ctx, nil)
+	require.Error(t, err)
+}
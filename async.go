@@ -0,0 +1,116 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// ErrInvokedAsync is returned by Invoke when the lambda function was invoked
+// as an 'Event' by WithAsyncFallback; the invocation was accepted, but its
+// result won't be returned.
+var ErrInvokedAsync = errors.New("invoked asynchronously, no result will be returned")
+
+// Result holds the outcome of an invocation which was performed
+// asynchronously.
+type Result struct {
+	Payload json.RawMessage
+	Err     error
+}
+
+// InvokeAsync invokes the lambda function in a new goroutine, returning a
+// channel which the Result will be sent on once the invocation completes. The
+// channel is closed after the Result has been sent.
+func (i *Invoker) InvokeAsync(ctx context.Context, body json.RawMessage) <-chan Result {
+	results := make(chan Result, 1)
+	go func() {
+		defer close(results)
+		payload, err := i.Invoke(ctx, body)
+		results <- Result{
+			Payload: payload,
+			Err:     err,
+		}
+	}()
+	return results
+}
+
+// InvokeAll invokes the lambda function once for each of bodies, with at most
+// concurrency invocations in flight at once. The Results are returned in the
+// same order as bodies, each holding the outcome of its own invocation. Once
+// ctx is done no further invocations are started; the Results of those
+// bodies hold the context's error, which is also returned.
+func (i *Invoker) InvokeAll(ctx context.Context, bodies []json.RawMessage, concurrency int) ([]Result, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]Result, len(bodies))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(bodies); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range indexes {
+				payload, err := i.Invoke(ctx, bodies[n])
+				results[n] = Result{
+					Payload: payload,
+					Err:     err,
+				}
+			}
+		}()
+	}
+	next := 0
+schedule:
+	for ; next < len(bodies); next++ {
+		select {
+		case <-ctx.Done():
+			break schedule
+		default:
+		}
+		select {
+		case indexes <- next:
+		case <-ctx.Done():
+			break schedule
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if next < len(bodies) {
+		for n := next; n < len(bodies); n++ {
+			results[n] = Result{Err: ctx.Err()}
+		}
+		return results, ctx.Err()
+	}
+	return results, nil
+}
+
+// WithAsyncFallback returns an option which can be passed when initializing an
+// Invoker. If provided, invocations made with a context which has less than
+// threshold remaining before its deadline are made as an 'Event' rather than
+// waiting for the result; Invoke returns ErrInvokedAsync once the invocation
+// has been accepted. Procedures configured by AsProcedure are called as an
+// event too, since the caller has accepted their response won't be returned.
+func WithAsyncFallback(threshold time.Duration) Option {
+	return func(i *Invoker) {
+		i.asyncThreshold = threshold
+	}
+}
+
+// fallbackToAsync changes input to be invoked as an 'Event' if ctx doesn't
+// leave enough time to wait for the result, returning whether it did.
+func (i *Invoker) fallbackToAsync(ctx context.Context, input *lambda.InvokeInput) bool {
+	if i.asyncThreshold <= 0 || aws.StringValue(input.InvocationType) != lambda.InvocationTypeRequestResponse {
+		return false
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) >= i.asyncThreshold {
+		return false
+	}
+	input.InvocationType = aws.String(lambda.InvocationTypeEvent)
+	return true
+}
@@ -0,0 +1,127 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeAsync(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: i.Payload,
+		}, nil
+	})
+	invoker := New(li, arn)
+	first := invoker.InvokeAsync(ctx, json.RawMessage(`1`))
+	second := invoker.InvokeAsync(ctx, json.RawMessage(`2`))
+	for expected, results := range map[string]<-chan Result{"1": first, "2": second} {
+		result, ok := <-results
+		require.True(t, ok)
+		require.NoError(t, result.Err)
+		assert.Equal(t, expected, string(result.Payload))
+		_, ok = <-results
+		assert.False(t, ok)
+	}
+}
+
+func TestInvokeAll(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var inFlight, maxInFlight int32
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		if string(i.Payload) == "3" {
+			return nil, assert.AnError
+		}
+		return &lambda.InvokeOutput{
+			Payload: i.Payload,
+		}, nil
+	})
+	var bodies []json.RawMessage
+	for n := 0; n < 10; n++ {
+		bodies = append(bodies, json.RawMessage(fmt.Sprint(n)))
+	}
+	results, err := New(li, arn).InvokeAll(ctx, bodies, 3)
+	require.NoError(t, err)
+	require.Len(t, results, len(bodies))
+	for n, result := range results {
+		if n == 3 {
+			assert.ErrorIs(t, result.Err, assert.AnError)
+			continue
+		}
+		require.NoError(t, result.Err)
+		assert.Equal(t, fmt.Sprint(n), string(result.Payload))
+	}
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(3))
+}
+
+func TestInvokeAllCancelled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		cancel()
+		return &lambda.InvokeOutput{
+			Payload: i.Payload,
+		}, nil
+	})
+	bodies := []json.RawMessage{json.RawMessage(`1`), json.RawMessage(`2`), json.RawMessage(`3`)}
+	results, err := New(li, arn).InvokeAll(ctx, bodies, 1)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Len(t, results, len(bodies))
+	assert.Equal(t, "1", string(results[0].Payload))
+	assert.True(t, errors.Is(results[2].Err, context.Canceled))
+}
+
+func TestInvokeWithAsyncFallback(t *testing.T) {
+	t.Parallel()
+	arn := "test-arn"
+	var invocationTypes []string
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		invocationTypes = append(invocationTypes, *i.InvocationType)
+		return &lambda.InvokeOutput{
+			Payload: []byte(`"result"`),
+		}, nil
+	})
+	invoker := New(li, arn, AsProcedure("Do", nil), WithAsyncFallback(time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	result, err := invoker.Invoke(ctx, nil)
+	assert.ErrorIs(t, err, ErrInvokedAsync)
+	assert.Nil(t, result)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	_, err = New(li, arn, WithAsyncFallback(time.Minute)).Invoke(ctx, nil)
+	require.NoError(t, err)
+	_, err = New(li, arn, WithAsyncFallback(time.Minute)).Invoke(context.Background(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		lambda.InvocationTypeEvent,
+		lambda.InvocationTypeRequestResponse,
+		lambda.InvocationTypeRequestResponse,
+	}, invocationTypes)
+}
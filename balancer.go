@@ -0,0 +1,150 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+)
+
+// Target is one of the Invokers a Balancer spreads invocations across.
+type Target struct {
+	Invoker *Invoker
+	// LastFailure is when invoking the target last failed, or zero if it
+	// hasn't.
+	LastFailure time.Time
+}
+
+// Strategy implementations choose which of a Balancer's targets to invoke.
+// They must be safe for concurrent use.
+type Strategy interface {
+	// Select returns the index of the target in targets to invoke next.
+	// targets is never empty; if the Balancer is failing over it only
+	// contains the targets which haven't been tried yet.
+	Select(targets []Target) int
+}
+
+// roundRobin selects each target in turn, skipping those which failed within
+// the cooldown.
+type roundRobin struct {
+	next     uint64
+	cooldown time.Duration
+}
+
+// RoundRobin returns a Strategy which selects each target in turn, skipping
+// any which failed within cooldown; if every target failed within cooldown
+// they're selected in turn regardless.
+func RoundRobin(cooldown time.Duration) Strategy {
+	return &roundRobin{cooldown: cooldown}
+}
+
+func (r *roundRobin) Select(targets []Target) int {
+	start := int((atomic.AddUint64(&r.next, 1) - 1) % uint64(len(targets)))
+	for n := 0; n < len(targets); n++ {
+		j := (start + n) % len(targets)
+		if time.Since(targets[j].LastFailure) >= r.cooldown {
+			return j
+		}
+	}
+	return start
+}
+
+// Balancer spreads invocations across several Invokers; for example the same
+// lambda function deployed in multiple regions.
+type Balancer struct {
+	mu       sync.Mutex
+	targets  []Target
+	strategy Strategy
+	failover bool
+}
+
+// BalancerOption implementations configure a Balancer.
+type BalancerOption func(*Balancer)
+
+// WithStrategy returns an option which can be passed when initializing a
+// Balancer. If provided s is used to select targets, rather than
+// RoundRobin(time.Minute).
+func WithStrategy(s Strategy) BalancerOption {
+	return func(b *Balancer) {
+		b.strategy = s
+	}
+}
+
+// WithFailover returns an option which can be passed when initializing a
+// Balancer. If provided, an invocation which fails without the lambda
+// function returning an error is retried with another target, until each has
+// been tried once.
+func WithFailover() BalancerOption {
+	return func(b *Balancer) {
+		b.failover = true
+	}
+}
+
+// NewBalancer initializes a Balancer which spreads invocations across
+// invokers, with the options passed.
+func NewBalancer(invokers []*Invoker, opts ...BalancerOption) *Balancer {
+	b := &Balancer{
+		targets:  make([]Target, 0, len(invokers)),
+		strategy: RoundRobin(time.Minute),
+	}
+	for _, invoker := range invokers {
+		b.targets = append(b.targets, Target{Invoker: invoker})
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Invoke invokes one of the Balancer's targets, selected by its Strategy, in
+// the same way as Invoker.Invoke. If failover is enabled and the invocation
+// fails, other targets are tried; the result of the last is returned. An error
+// is returned if the Strategy selects an index which is out of range.
+func (b *Balancer) Invoke(ctx context.Context, body json.RawMessage, opts ...awsreq.Option) (json.RawMessage, error) {
+	if len(b.targets) == 0 {
+		return nil, errors.New("balancer has no targets")
+	}
+	untried := make([]int, len(b.targets))
+	for n := range untried {
+		untried[n] = n
+	}
+	for {
+		b.mu.Lock()
+		candidates := make([]Target, 0, len(untried))
+		for _, n := range untried {
+			candidates = append(candidates, b.targets[n])
+		}
+		b.mu.Unlock()
+		selected := b.strategy.Select(candidates)
+		if selected < 0 || selected >= len(candidates) {
+			return nil, fmt.Errorf("strategy selected target %d of %d", selected, len(candidates))
+		}
+		target := untried[selected]
+		payload, err := b.targets[target].Invoker.Invoke(ctx, body, opts...)
+		if !targetFailed(err) {
+			return payload, err
+		}
+		b.mu.Lock()
+		b.targets[target].LastFailure = time.Now()
+		b.mu.Unlock()
+		untried = append(untried[:selected], untried[selected+1:]...)
+		if !b.failover || len(untried) == 0 || ctx.Err() != nil {
+			return payload, err
+		}
+	}
+}
+
+// targetFailed reports whether err means the target itself failed, rather
+// than the lambda function returning an error or the caller cancelling.
+func targetFailed(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	var ierr *InvocationError
+	return errors.As(err, &ierr) && ierr.Kind != KindFunction && ierr.Kind != KindCancelled
+}
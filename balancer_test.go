@@ -0,0 +1,100 @@
+package invoker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalancerRoundRobin(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var invoked []string
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		invoked = append(invoked, *i.FunctionName)
+		return &lambda.InvokeOutput{}, nil
+	})
+	b := NewBalancer([]*Invoker{New(li, "a"), New(li, "b"), New(li, "c")})
+	for n := 0; n < 4; n++ {
+		_, err := b.Invoke(ctx, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "a"}, invoked)
+}
+
+func TestBalancerSkipsFailed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var invoked []string
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		invoked = append(invoked, *i.FunctionName)
+		if *i.FunctionName == "a" {
+			return nil, assert.AnError
+		}
+		return &lambda.InvokeOutput{}, nil
+	})
+	b := NewBalancer([]*Invoker{New(li, "a"), New(li, "b")}, WithStrategy(RoundRobin(time.Hour)))
+	_, err := b.Invoke(ctx, nil)
+	require.Error(t, err)
+	for n := 0; n < 2; n++ {
+		_, err = b.Invoke(ctx, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"a", "b", "b"}, invoked)
+}
+
+func TestBalancerFailover(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var invoked []string
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		invoked = append(invoked, *i.FunctionName)
+		switch *i.FunctionName {
+		case "a":
+			return nil, assert.AnError
+		}
+		return &lambda.InvokeOutput{FunctionError: aws.String("Unhandled")}, nil
+	})
+	b := NewBalancer([]*Invoker{New(li, "a"), New(li, "b")}, WithFailover())
+	_, err := b.Invoke(ctx, nil)
+	require.Error(t, err)
+	var e *Error
+	assert.ErrorAs(t, err, &e)
+	assert.Equal(t, []string{"a", "b"}, invoked)
+}
+
+type fixedStrategy int
+
+func (s fixedStrategy) Select([]Target) int {
+	return int(s)
+}
+
+func TestBalancerInvalidSelection(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Error("lambda function shouldn't be invoked")
+		return &lambda.InvokeOutput{}, nil
+	})
+	for _, selected := range []int{-1, 2} {
+		b := NewBalancer([]*Invoker{New(li, "a"), New(li, "b")}, WithStrategy(fixedStrategy(selected)))
+		_, err := b.Invoke(ctx, nil)
+		assert.Error(t, err)
+	}
+}
+
+func TestTargetFailed(t *testing.T) {
+	t.Parallel()
+	assert.True(t, targetFailed(ErrCircuitOpen))
+	assert.True(t, targetFailed(&InvocationError{Kind: KindThrottled, Err: errors.New("slow down")}))
+	assert.False(t, targetFailed(&InvocationError{Kind: KindFunction, Err: errors.New("failed")}))
+	assert.False(t, targetFailed(&InvocationError{Kind: KindCancelled, Err: context.Canceled}))
+	assert.False(t, targetFailed(errors.New("mutator failed")))
+}
@@ -0,0 +1,39 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrBatchSizeMismatch is returned by BatchInvoke if the lambda function
+// doesn't return exactly one result per item.
+var ErrBatchSizeMismatch = errors.New("batch response doesn't have one result per item")
+
+// BatchInvoke invokes the lambda function once with items as a JSON array,
+// splitting the array it returns into per-item results; the function must
+// return a result for each item, in the same order. If the Invoker was
+// initialized with AsProcedure the array is sent as the procedure's body, so
+// the procedure should be one which handles a batch.
+func (i *Invoker) BatchInvoke(ctx context.Context, items []json.RawMessage) ([]json.RawMessage, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+	resp, err := i.Invoke(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	var results []json.RawMessage
+	if err := json.Unmarshal(resp, &results); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal batch response: %w", err)
+	}
+	if len(results) != len(items) {
+		return nil, fmt.Errorf("%w: got %d results for %d items", ErrBatchSizeMismatch, len(results), len(items))
+	}
+	return results, nil
+}
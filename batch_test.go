@@ -0,0 +1,70 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	router "github.com/edstell/lambda-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchInvoke(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	invocations := 0
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		invocations++
+		var items []int
+		require.NoError(t, json.Unmarshal(input.Payload, &items))
+		results := make([]int, len(items))
+		for j, item := range items {
+			results[j] = item * 2
+		}
+		payload, err := json.Marshal(results)
+		require.NoError(t, err)
+		return &lambda.InvokeOutput{
+			Payload: payload,
+		}, nil
+	})
+	results, err := New(li, arn).BatchInvoke(ctx, []json.RawMessage{[]byte(`1`), []byte(`2`), []byte(`3`)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, invocations)
+	assert.Equal(t, []json.RawMessage{[]byte(`2`), []byte(`4`), []byte(`6`)}, results)
+}
+
+func TestBatchInvokeSizeMismatch(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: []byte(`[{}]`),
+		}, nil
+	})
+	_, err := New(li, arn).BatchInvoke(ctx, []json.RawMessage{[]byte(`{}`), []byte(`{}`)})
+	assert.ErrorIs(t, err, ErrBatchSizeMismatch)
+}
+
+func TestBatchInvokeAsProcedure(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		req := router.Request{}
+		require.NoError(t, json.Unmarshal(input.Payload, &req))
+		assert.Equal(t, "BatchDo", req.Procedure)
+		assert.JSONEq(t, `[{"id":1},{"id":2}]`, string(req.Body))
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"body":[{"ok":true},{"ok":false}]}`),
+		}, nil
+	})
+	results, err := New(li, arn, AsProcedure("BatchDo", nil)).BatchInvoke(ctx, []json.RawMessage{[]byte(`{"id":1}`), []byte(`{"id":2}`)})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.JSONEq(t, `{"ok":false}`, string(results[1]))
+}
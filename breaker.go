@@ -0,0 +1,135 @@
+package invoker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// ErrCircuitOpen is returned by Invoke, without invoking the lambda function,
+// while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerConfig configures a circuit breaker.
+type BreakerConfig struct {
+	// Threshold is the number of consecutive failed attempts after which the
+	// breaker opens.
+	Threshold int
+	// Cooldown is how long the breaker stays open for before allowing a trial
+	// invocation through.
+	Cooldown time.Duration
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker tracks consecutive failures, and once the threshold has been
+// reached rejects invocations until the cooldown has passed. After the
+// cooldown a single trial invocation is allowed; if it succeeds the breaker
+// closes, otherwise it opens again.
+type circuitBreaker struct {
+	cfg      BreakerConfig
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// WithCircuitBreaker returns an option which can be passed when initializing
+// an Invoker. If provided, once cfg.Threshold consecutive attempts to invoke
+// the lambda function have failed (with an error returned by the
+// LambdaInvoker, a FunctionError or a 5xx status code) Invoke will return
+// ErrCircuitOpen for cfg.Cooldown without invoking the lambda function.
+// Attempts which fail before the LambdaInvoker is called, for example because
+// of the rate limit or the bulkhead, aren't counted.
+func WithCircuitBreaker(cfg BreakerConfig) Option {
+	return func(i *Invoker) {
+		if cfg.Threshold < 1 {
+			cfg.Threshold = 1
+		}
+		i.breaker = &circuitBreaker{cfg: cfg}
+	}
+}
+
+// allow reports whether an invocation should be attempted.
+func (b *circuitBreaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cfg.Cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+// record updates the state of the breaker with the outcome of a call to the
+// LambdaInvoker. Cancelled calls say nothing about the function's health so
+// aren't counted; but if the trial invocation is cancelled the breaker
+// reopens, without restarting the cooldown, so the next invocation is the
+// trial.
+func (b *circuitBreaker) record(output *lambda.InvokeOutput, err error) {
+	if b == nil {
+		return
+	}
+	if errors.Is(err, context.Canceled) {
+		b.abandon()
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed(output, err) {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.cfg.Threshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// abandon is called when an allowed invocation doesn't call the
+// LambdaInvoker, for example because it was rate limited or the bulkhead was
+// full; it isn't counted, but if it was the trial invocation the breaker
+// reopens without restarting the cooldown.
+func (b *circuitBreaker) abandon() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerHalfOpen {
+		b.state = breakerOpen
+	}
+}
+
+// failed reports whether an invocation should count as a failure.
+func failed(output *lambda.InvokeOutput, err error) bool {
+	if err != nil {
+		return true
+	}
+	if output == nil {
+		return false
+	}
+	return output.FunctionError != nil || aws.Int64Value(output.StatusCode) >= 500
+}
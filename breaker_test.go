@@ -0,0 +1,139 @@
+package invoker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestInvokeWithCircuitBreaker(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var mu sync.Mutex
+	failing, attempts := true, 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if failing {
+			return &lambda.InvokeOutput{
+				FunctionError: aws.String("Unhandled"),
+			}, nil
+		}
+		return &lambda.InvokeOutput{}, nil
+	})
+	cooldown := 20 * time.Millisecond
+	invoker := New(li, arn, WithCircuitBreaker(BreakerConfig{
+		Threshold: 2,
+		Cooldown:  cooldown,
+	}))
+	for j := 0; j < 2; j++ {
+		_, err := invoker.Invoke(ctx, nil)
+		require.Error(t, err)
+		assert.NotEqual(t, ErrCircuitOpen, err)
+	}
+	_, err := invoker.Invoke(ctx, nil)
+	assert.Equal(t, ErrCircuitOpen, err)
+	assert.Equal(t, 2, attempts)
+
+	// A failed trial invocation re-opens the breaker.
+	time.Sleep(cooldown)
+	_, err = invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.NotEqual(t, ErrCircuitOpen, err)
+	_, err = invoker.Invoke(ctx, nil)
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	// A successful trial invocation closes it.
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	time.Sleep(cooldown)
+	_, err = invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	_, err = invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5, attempts)
+}
+
+func TestInvokeWithCircuitBreakerCancelledTrial(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var mu sync.Mutex
+	failing := true
+	li := LambdaInvokerFunc(func(ctx context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if failing {
+			return &lambda.InvokeOutput{
+				FunctionError: aws.String("Unhandled"),
+			}, nil
+		}
+		return &lambda.InvokeOutput{}, nil
+	})
+	cooldown := 20 * time.Millisecond
+	invoker := New(li, arn, WithCircuitBreaker(BreakerConfig{
+		Threshold: 1,
+		Cooldown:  cooldown,
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.NotEqual(t, ErrCircuitOpen, err)
+
+	// The trial invocation is cancelled, which neither opens nor closes the
+	// breaker; the next invocation is allowed through as the trial.
+	time.Sleep(cooldown)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = invoker.Invoke(cancelled, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	_, err = invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	_, err = invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+}
+
+func TestInvokeWithCircuitBreakerLocalFailures(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithCircuitBreaker(BreakerConfig{
+		Threshold: 1,
+		Cooldown:  time.Hour,
+	}), WithRateLimit(rate.Every(time.Hour), 1))
+	_, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+
+	// The rate limiter can't allow another invocation before the deadline,
+	// and the expired context fails before the function is called; neither
+	// counts against the function.
+	limited, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	_, err = invoker.Invoke(limited, nil)
+	require.Error(t, err)
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+	_, err = invoker.Invoke(expired, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, breakerClosed, invoker.breaker.state)
+}
@@ -0,0 +1,38 @@
+package invoker
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// WithMaxConcurrency returns an option which can be passed when initializing
+// an Invoker. If provided at most n invocations of the lambda function will be
+// in flight at once; further attempts block until one completes, or the
+// context passed is done.
+func WithMaxConcurrency(n int) Option {
+	return func(i *Invoker) {
+		if n < 1 {
+			n = 1
+		}
+		i.slots = make(chan struct{}, n)
+	}
+}
+
+// acquire blocks until an invocation may be made, returning a func which must
+// be called once it completes. If ctx is done first its error is returned.
+func (i *Invoker) acquire(ctx context.Context) (func(), error) {
+	if i.slots != nil {
+		select {
+		case i.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	atomic.AddInt64(&i.counters.inFlight, 1)
+	return func() {
+		atomic.AddInt64(&i.counters.inFlight, -1)
+		if i.slots != nil {
+			<-i.slots
+		}
+	}, nil
+}
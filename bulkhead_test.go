@@ -0,0 +1,72 @@
+package invoker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithMaxConcurrency(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		started <- struct{}{}
+		<-unblock
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithMaxConcurrency(2))
+	wg := sync.WaitGroup{}
+	for n := 0; n < 3; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := invoker.Invoke(ctx, nil)
+			assert.NoError(t, err)
+		}()
+	}
+	<-started
+	<-started
+	select {
+	case <-started:
+		t.Fatal("more than 2 invocations in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, int64(2), invoker.Stats().InFlight)
+	close(unblock)
+	<-started
+	wg.Wait()
+	assert.Equal(t, int64(0), invoker.Stats().InFlight)
+	assert.Equal(t, int64(3), invoker.Stats().Successes)
+}
+
+func TestInvokeWithMaxConcurrencyCancelled(t *testing.T) {
+	t.Parallel()
+	arn := "test-arn"
+	unblock := make(chan struct{})
+	defer close(unblock)
+	started := make(chan struct{})
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		close(started)
+		<-unblock
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithMaxConcurrency(1))
+	go func() {
+		_, _ = invoker.Invoke(context.Background(), nil)
+	}()
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,135 @@
+package invoker
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// Cache implementations store the results of invocations. They must be safe
+// for concurrent use.
+type Cache interface {
+	// Get returns the value stored for key, if it hasn't expired.
+	Get(key string) (json.RawMessage, bool)
+	// Set stores value for key, expiring it after ttl.
+	Set(key string, value json.RawMessage, ttl time.Duration)
+}
+
+// WithCache returns an option which can be passed when initializing an
+// Invoker. If provided the result of each successful invocation will be
+// stored in cache for ttl, keyed on a hash of the function name, qualifier and
+// payload sent. Invocations with a cached result return it without invoking
+// the lambda function. Errors are never cached, nor are 'Event' or 'DryRun'
+// invocations since they have no result. It should only be used for functions
+// whose result depends on nothing but their input.
+func WithCache(cache Cache, ttl time.Duration) Option {
+	return func(i *Invoker) {
+		i.cache = cache
+		i.cacheTTL = ttl
+	}
+}
+
+// cacheKey returns the key the result of invoking the lambda function with
+// input is cached under, or false if it shouldn't be cached.
+func (i *Invoker) cacheKey(input *lambda.InvokeInput) (string, bool) {
+	if i.cache == nil || aws.StringValue(input.InvocationType) != lambda.InvocationTypeRequestResponse {
+		return "", false
+	}
+	h := sha256.New()
+	h.Write([]byte(aws.StringValue(input.FunctionName)))
+	h.Write([]byte{0})
+	h.Write([]byte(aws.StringValue(input.Qualifier)))
+	h.Write([]byte{0})
+	h.Write(i.canonicalKeyPayload(input.Payload))
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// WithKeyCanonicalizer returns an option which can be passed when
+// initializing an Invoker. If provided canonicalize is applied to payloads
+// before the keys used by WithCache and WithIdempotency are derived from
+// them, so payloads which are logically identical share a key; CanonicalJSON
+// is a suitable canonicalizer. The payload sent to the lambda function isn't
+// modified.
+func WithKeyCanonicalizer(canonicalize func(json.RawMessage) json.RawMessage) Option {
+	return func(i *Invoker) {
+		i.canonicalize = canonicalize
+	}
+}
+
+// canonicalKeyPayload returns payload in the canonical form keys are derived
+// from, if the Invoker has a canonicalizer.
+func (i *Invoker) canonicalKeyPayload(payload json.RawMessage) json.RawMessage {
+	if i.canonicalize == nil {
+		return payload
+	}
+	return i.canonicalize(payload)
+}
+
+// CanonicalJSON returns payload re-marshaled with object keys sorted and
+// insignificant whitespace removed; numbers are preserved as they were sent.
+// If payload isn't valid JSON it's returned untouched.
+func CanonicalJSON(payload json.RawMessage) json.RawMessage {
+	d := json.NewDecoder(bytes.NewReader(payload))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil || d.More() {
+		return payload
+	}
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return payload
+	}
+	return canonical
+}
+
+// MemoryCache is a Cache which stores values in memory. Expired values are
+// removed when they're next looked up.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	value   json.RawMessage
+	expires time.Time
+}
+
+// NewMemoryCache initializes an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: map[string]cacheEntry{},
+		now:     time.Now,
+	}
+}
+
+// Get returns the value stored for key, if it hasn't expired.
+func (c *MemoryCache) Get(key string) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set stores value for key, expiring it after ttl.
+func (c *MemoryCache) Set(key string, value json.RawMessage, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{
+		value:   value,
+		expires: c.now().Add(ttl),
+	}
+}
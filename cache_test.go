@@ -0,0 +1,135 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var mu sync.Mutex
+	calls := 0
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return &lambda.InvokeOutput{
+			Payload: i.Payload,
+		}, nil
+	})
+	invoker := New(li, arn, WithCache(NewMemoryCache(), time.Minute))
+	var wg sync.WaitGroup
+	for n := 0; n < 5; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := invoker.Invoke(ctx, []byte(`"warm"`))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	mu.Lock()
+	warmed := calls
+	mu.Unlock()
+
+	result, err := invoker.Invoke(ctx, []byte(`"warm"`))
+	require.NoError(t, err)
+	assert.Equal(t, `"warm"`, string(result))
+	_, err = invoker.InvokeWith(ctx, []byte(`"warm"`), CallQualifier("prod"))
+	require.NoError(t, err)
+	_, err = invoker.Invoke(ctx, []byte(`"other"`))
+	require.NoError(t, err)
+	assert.Equal(t, warmed+2, calls)
+}
+
+func TestInvokeWithCacheError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	calls := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		calls++
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+		}, nil
+	})
+	invoker := New(li, arn, WithCache(NewMemoryCache(), time.Minute))
+	for n := 0; n < 2; n++ {
+		_, err := invoker.Invoke(ctx, nil)
+		require.Error(t, err)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	cache := NewMemoryCache()
+	cache.now = func() time.Time {
+		return now
+	}
+	cache.Set("key", []byte(`"value"`), time.Second)
+	value, ok := cache.Get("key")
+	require.True(t, ok)
+	assert.Equal(t, `"value"`, string(value))
+
+	now = now.Add(time.Second)
+	_, ok = cache.Get("key")
+	assert.False(t, ok)
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	t.Parallel()
+	for payload, expected := range map[string]string{
+		`{"b":1, "a":{"d":[2,1],"c":null}}`: `{"a":{"c":null,"d":[2,1]},"b":1}`,
+		`{"n":12345678901234567890.10}`:     `{"n":12345678901234567890.10}`,
+		` "value" `:                         `"value"`,
+		`{"invalid"`:                        `{"invalid"`,
+		`{} {}`:                             `{} {}`,
+	} {
+		assert.Equal(t, expected, string(CanonicalJSON([]byte(payload))))
+	}
+}
+
+func TestInvokeWithKeyCanonicalizer(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var sent []string
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		sent = append(sent, string(i.Payload))
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{}`),
+		}, nil
+	})
+	keys := []string{}
+	invoker := New(li, arn, WithKeyCanonicalizer(CanonicalJSON), WithCache(NewMemoryCache(), time.Minute), WithIdempotency(func(body json.RawMessage) string {
+		keys = append(keys, string(body))
+		return string(body)
+	}, NewMemoryCache(), time.Minute))
+	for _, body := range []string{`{"a":1,"b":2}`, `{"b":2, "a":1}`} {
+		_, err := invoker.Invoke(ctx, []byte(body))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{`{"a":1,"b":2}`}, sent)
+	assert.Equal(t, []string{`{"a":1,"b":2}`, `{"a":1,"b":2}`}, keys)
+
+	sent = nil
+	invoker = New(li, arn, WithCache(NewMemoryCache(), time.Minute))
+	for _, body := range []string{`{"a":1,"b":2}`, `{"b":2, "a":1}`} {
+		_, err := invoker.Invoke(ctx, []byte(body))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{`{"a":1,"b":2}`, `{"b":2, "a":1}`}, sent)
+}
@@ -0,0 +1,96 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// callConfig holds the configuration of a single call to the Invoker, which
+// overrides that of the Invoker itself.
+type callConfig struct {
+	invocationType string
+	qualifier      string
+	timeout        time.Duration
+	clientContext  string
+	requestOptions []awsreq.Option
+	// skipDefaultOnError returns errors as they are, rather than passing
+	// them to the defaultOnError of the Invoker.
+	skipDefaultOnError bool
+}
+
+// CallOption implementations can override how a single invocation of a lambda
+// function is performed, without modifying the Invoker.
+type CallOption func(*callConfig)
+
+// CallInvocationType overrides the InvocationType of a single invocation.
+func CallInvocationType(invocationType string) CallOption {
+	return func(c *callConfig) {
+		c.invocationType = invocationType
+	}
+}
+
+// CallQualifier overrides the version or alias invoked by a single
+// invocation.
+func CallQualifier(qualifier string) CallOption {
+	return func(c *callConfig) {
+		c.qualifier = qualifier
+	}
+}
+
+// CallTimeout overrides the timeout of a single invocation.
+func CallTimeout(d time.Duration) CallOption {
+	return func(c *callConfig) {
+		c.timeout = d
+	}
+}
+
+// CallClientContext overrides the ClientContext of a single invocation; the
+// clientContext should be JSON, and will be base64 encoded.
+func CallClientContext(clientContext string) CallOption {
+	return func(c *callConfig) {
+		c.clientContext = clientContext
+	}
+}
+
+// CallRequestOptions adds aws sdk request options to a single invocation,
+// applied after any passed by WithRequestOptions.
+func CallRequestOptions(opts ...awsreq.Option) CallOption {
+	return func(c *callConfig) {
+		c.requestOptions = append(c.requestOptions, opts...)
+	}
+}
+
+// InvokeWith invokes the lambda function in the same way as Invoke, but with
+// the CallOptions passed overriding the configuration of the Invoker for this
+// call only.
+func (i *Invoker) InvokeWith(ctx context.Context, body json.RawMessage, opts ...CallOption) (json.RawMessage, error) {
+	call := &callConfig{}
+	for _, opt := range opts {
+		opt(call)
+	}
+	payload, _, err := i.call(ctx, body, call)
+	return payload, err
+}
+
+// mutateInput applies the overrides of the call to input.
+func (c *callConfig) mutateInput(input *lambda.InvokeInput) error {
+	if c.invocationType != "" {
+		input.InvocationType = aws.String(c.invocationType)
+	}
+	if c.qualifier != "" {
+		input.Qualifier = aws.String(c.qualifier)
+	}
+	if c.clientContext != "" {
+		clientContext, err := encodeClientContext(c.clientContext)
+		if err != nil {
+			return err
+		}
+		input.ClientContext = aws.String(clientContext)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package invoker
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWith(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var inputs []*lambda.InvokeInput
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		inputs = append(inputs, i)
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithQualifier("prod"))
+	_, err := invoker.InvokeWith(ctx, nil,
+		CallInvocationType(lambda.InvocationTypeEvent),
+		CallQualifier("canary"),
+		CallClientContext(`{"custom":{}}`),
+	)
+	require.NoError(t, err)
+	_, err = invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+
+	require.Len(t, inputs, 2)
+	assert.Equal(t, lambda.InvocationTypeEvent, *inputs[0].InvocationType)
+	assert.Equal(t, "canary", *inputs[0].Qualifier)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(`{"custom":{}}`)), *inputs[0].ClientContext)
+	// The invoker itself isn't modified.
+	assert.Equal(t, lambda.InvocationTypeRequestResponse, *inputs[1].InvocationType)
+	assert.Equal(t, "prod", *inputs[1].Qualifier)
+	assert.Nil(t, inputs[1].ClientContext)
+}
+
+func TestInvokeWithCallTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(ctx context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	invoker := New(li, arn, WithTimeout(time.Minute))
+	_, err := invoker.InvokeWith(ctx, nil, CallTimeout(10*time.Millisecond))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestInvokeWithRequestOptions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var applied []string
+	option := func(name string) awsreq.Option {
+		return func(*awsreq.Request) {
+			applied = append(applied, name)
+		}
+	}
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, opts ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		r := &awsreq.Request{}
+		for _, opt := range opts {
+			opt(r)
+		}
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithRequestOptions(option("invoker")))
+	_, err := invoker.InvokeWith(ctx, nil, CallRequestOptions(option("call")))
+	require.NoError(t, err)
+	_, err = invoker.Invoke(ctx, nil, option("invoke"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"invoker", "call", "invoker", "invoke"}, applied)
+}
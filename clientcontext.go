@@ -0,0 +1,106 @@
+package invoker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// maxClientContextSize is the maximum size of the base64 encoded
+// ClientContext accepted by lambda.
+const maxClientContextSize = 3583
+
+// WithClientContext returns an option which can be passed when initializing
+// an Invoker. If provided, fn will be called with the context passed to
+// Invoke, and the JSON it returns will be passed to the lambda function as its
+// ClientContext. This is useful for propagating trace headers. If fn returns
+// an empty string no ClientContext is set.
+func WithClientContext(fn func(ctx context.Context) string) Option {
+	return func(i *Invoker) {
+		i.inputMutators = append(i.inputMutators, func(ctx context.Context, input *lambda.InvokeInput) error {
+			clientContext := fn(ctx)
+			if clientContext == "" {
+				return nil
+			}
+			encoded, err := encodeClientContext(clientContext)
+			if err != nil {
+				return err
+			}
+			input.ClientContext = aws.String(encoded)
+			return nil
+		})
+	}
+}
+
+// WithContextMetadata returns an option which can be passed when initializing
+// an Invoker. If provided, extract will be called with the context passed to
+// Invoke, and the values it returns will be added to the 'custom' field of the
+// lambda function's ClientContext; for example to forward a correlation ID.
+// The router.Request used by AsProcedure has nowhere to carry metadata, so
+// the ClientContext is used regardless of how the function is invoked. Values
+// are merged into any ClientContext set by an earlier option, so it should be
+// passed after WithClientContext.
+func WithContextMetadata(extract func(ctx context.Context) map[string]string) Option {
+	return func(i *Invoker) {
+		i.inputMutators = append(i.inputMutators, func(ctx context.Context, input *lambda.InvokeInput) error {
+			metadata := extract(ctx)
+			if len(metadata) == 0 {
+				return nil
+			}
+			return addClientContextCustom(input, metadata)
+		})
+	}
+}
+
+// addClientContextCustom merges values into the 'custom' field of the
+// ClientContext of input.
+func addClientContextCustom(input *lambda.InvokeInput, values map[string]string) error {
+	clientContext := map[string]json.RawMessage{}
+	if input.ClientContext != nil {
+		decoded, err := base64.StdEncoding.DecodeString(*input.ClientContext)
+		if err != nil {
+			return fmt.Errorf("decoding client context: %w", err)
+		}
+		if err := json.Unmarshal(decoded, &clientContext); err != nil {
+			return fmt.Errorf("unmarshaling client context: %w", err)
+		}
+	}
+	custom := map[string]string{}
+	if raw, ok := clientContext["custom"]; ok {
+		if err := json.Unmarshal(raw, &custom); err != nil {
+			return fmt.Errorf("unmarshaling client context custom: %w", err)
+		}
+	}
+	for k, v := range values {
+		custom[k] = v
+	}
+	raw, err := json.Marshal(custom)
+	if err != nil {
+		return err
+	}
+	clientContext["custom"] = raw
+	bytes, err := json.Marshal(clientContext)
+	if err != nil {
+		return err
+	}
+	encoded, err := encodeClientContext(string(bytes))
+	if err != nil {
+		return err
+	}
+	input.ClientContext = aws.String(encoded)
+	return nil
+}
+
+// encodeClientContext base64 encodes clientContext, checking it doesn't
+// exceed the size lambda accepts.
+func encodeClientContext(clientContext string) (string, error) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(clientContext))
+	if len(encoded) > maxClientContextSize {
+		return "", fmt.Errorf("client context is %d bytes once encoded, exceeding the limit of %d", len(encoded), maxClientContextSize)
+	}
+	return encoded, nil
+}
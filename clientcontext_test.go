@@ -0,0 +1,81 @@
+package invoker
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type traceKey struct{}
+
+func TestInvokeWithClientContext(t *testing.T) {
+	t.Parallel()
+	ctx := context.WithValue(context.Background(), traceKey{}, "Root=1-abc")
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		require.NotNil(t, i.ClientContext)
+		decoded, err := base64.StdEncoding.DecodeString(*i.ClientContext)
+		require.NoError(t, err)
+		assert.Equal(t, `{"custom":{"trace":"Root=1-abc"}}`, string(decoded))
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithClientContext(func(ctx context.Context) string {
+		return `{"custom":{"trace":"` + ctx.Value(traceKey{}).(string) + `"}}`
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+}
+
+func TestInvokeWithClientContextTooLarge(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda should not be invoked")
+		return nil, nil
+	})
+	invoker := New(li, arn, WithClientContext(func(context.Context) string {
+		return `{"custom":{"large":"` + strings.Repeat("a", maxClientContextSize) + `"}}`
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+}
+
+type correlationKey struct{}
+
+func TestInvokeWithContextMetadata(t *testing.T) {
+	t.Parallel()
+	ctx := context.WithValue(context.Background(), correlationKey{}, "abc-123")
+	arn := "test-arn"
+	var clientContext string
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		require.NotNil(t, i.ClientContext)
+		decoded, err := base64.StdEncoding.DecodeString(*i.ClientContext)
+		require.NoError(t, err)
+		clientContext = string(decoded)
+		return &lambda.InvokeOutput{}, nil
+	})
+	extract := func(ctx context.Context) map[string]string {
+		return map[string]string{"correlationId": ctx.Value(correlationKey{}).(string)}
+	}
+
+	_, err := New(li, arn, WithContextMetadata(extract)).Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"custom":{"correlationId":"abc-123"}}`, clientContext)
+
+	invoker := New(li, arn,
+		WithClientContext(func(context.Context) string {
+			return `{"custom":{"trace":"Root=1-abc"},"env":{"locale":"en"}}`
+		}),
+		WithContextMetadata(extract),
+	)
+	_, err = invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"custom":{"trace":"Root=1-abc","correlationId":"abc-123"},"env":{"locale":"en"}}`, clientContext)
+}
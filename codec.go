@@ -0,0 +1,45 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Codec implementations marshal and unmarshal the envelopes Options such as
+// AsProcedure wrap payloads in.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// jsonCodec is the default Codec, it uses encoding/json.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// WithCodec returns an option which can be passed when initializing an
+// Invoker. If provided, c will be used in place of encoding/json to marshal
+// and unmarshal envelopes.
+func WithCodec(c Codec) Option {
+	return func(i *Invoker) {
+		i.codec = c
+	}
+}
+
+// InvokeValue marshals v with the Invoker's Codec and invokes the lambda
+// function with the result, in the same way as Invoke. If v can't be
+// marshaled an error is returned without invoking the function.
+func (i *Invoker) InvokeValue(ctx context.Context, v interface{}) (json.RawMessage, error) {
+	body, err := i.codec.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+	return i.Invoke(ctx, body)
+}
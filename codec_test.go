@@ -0,0 +1,81 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingCodec struct {
+	jsonCodec
+	marshals, unmarshals int
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshals++
+	return c.jsonCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshals++
+	return c.jsonCodec.Unmarshal(data, v)
+}
+
+func TestInvokeAsProcedureWithCodec(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: json.RawMessage(`{"body":"result"}`),
+		}, nil
+	})
+	codec := &countingCodec{}
+	invoker := New(li, arn, AsProcedure("Do", func(e json.RawMessage) error {
+		return errors.New(string(e))
+	}), WithCodec(codec))
+	result, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `"result"`, string(result))
+	assert.Equal(t, 1, codec.marshals)
+	assert.Equal(t, 1, codec.unmarshals)
+}
+
+func TestInvokeValue(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: i.Payload,
+		}, nil
+	})
+	codec := &countingCodec{}
+	invoker := New(li, arn, WithCodec(codec))
+	result, err := invoker.InvokeValue(ctx, struct {
+		Name string `json:"name"`
+	}{Name: "lambda"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"lambda"}`, string(result))
+	assert.Equal(t, 1, codec.marshals)
+}
+
+func TestInvokeValueMarshalError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda should not be invoked")
+		return nil, nil
+	})
+	_, err := New(li, arn).InvokeValue(ctx, make(chan int))
+	require.Error(t, err)
+	var jsonErr *json.UnsupportedTypeError
+	assert.True(t, errors.As(err, &jsonErr))
+}
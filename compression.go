@@ -0,0 +1,132 @@
+package invoker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// minCompressionSize is the smallest payload which will be compressed; gzip
+// and base64 overheads make compressing smaller payloads pointless.
+const minCompressionSize = 1024
+
+// contentEncodingGzip marks a compressedPayload as gzipped.
+const contentEncodingGzip = "gzip"
+
+// compressedPayload is the envelope a compressed payload is sent in. Lambda
+// payloads must be JSON, so the compressed bytes are base64 encoded.
+type compressedPayload struct {
+	ContentEncoding string `json:"contentEncoding"`
+	Data            []byte `json:"data"`
+}
+
+// WithCompression returns an option which can be passed when initializing an
+// Invoker. If provided payloads larger than 1KB will be gzipped, if doing so
+// makes them smaller, and sent to the lambda function as:
+//
+//	{"contentEncoding":"gzip","data":"<base64 encoded gzipped payload>"}
+//
+// The lambda function must detect this envelope and decompress the payload.
+// It may compress its response in the same way, which will be decompressed
+// transparently. When combined with AsProcedure it should be passed after, so
+// that the router.Request is compressed.
+func WithCompression() Option {
+	return func(i *Invoker) {
+		i.inputMutators = append(i.inputMutators, func(_ context.Context, input *lambda.InvokeInput) error {
+			if len(input.Payload) < minCompressionSize {
+				return nil
+			}
+			compressed, err := compress(input.Payload)
+			if err != nil {
+				return err
+			}
+			if len(compressed) < len(input.Payload) {
+				input.Payload = compressed
+			}
+			return nil
+		})
+		i.outputMutators = append(i.outputMutators, func(output *lambda.InvokeOutput) error {
+			if output.FunctionError != nil {
+				return nil
+			}
+			payload, err := decompress(output.Payload)
+			if err != nil {
+				return err
+			}
+			output.Payload = payload
+			return nil
+		})
+	}
+}
+
+// gzipMagic are the first bytes of gzipped data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// WithResponseDecompression returns an option which can be passed when
+// initializing an Invoker. If provided payloads returned by the lambda
+// function which are gzipped, either as raw bytes or in the envelope described
+// by WithCompression, will be decompressed before the response decoder and
+// any output mutators, including AsProcedure's, are applied. Other payloads
+// are left untouched. Unlike WithCompression payloads sent to the function
+// aren't compressed.
+func WithResponseDecompression() Option {
+	return func(i *Invoker) {
+		i.decompressResponse = true
+	}
+}
+
+// decompressResponse gunzips payload if it's gzipped, or unwraps and gunzips
+// it if it's a compressedPayload; otherwise it's returned untouched.
+func decompressResponse(payload []byte) ([]byte, error) {
+	if bytes.HasPrefix(payload, gzipMagic) {
+		return gunzip(payload)
+	}
+	return decompress(payload)
+}
+
+// compress gzips payload, wrapping it in a compressedPayload.
+func compress(payload []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(compressedPayload{
+		ContentEncoding: contentEncodingGzip,
+		Data:            buf.Bytes(),
+	})
+}
+
+// decompress unwraps and gunzips payload if it's a compressedPayload,
+// otherwise it's returned untouched.
+func decompress(payload []byte) ([]byte, error) {
+	if len(payload) == 0 || payload[0] != '{' {
+		return payload, nil
+	}
+	compressed := compressedPayload{}
+	if err := json.Unmarshal(payload, &compressed); err != nil || compressed.ContentEncoding != contentEncodingGzip {
+		return payload, nil
+	}
+	return gunzip(compressed.Data)
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress payload: %w", err)
+	}
+	defer r.Close()
+	payload, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress payload: %w", err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,86 @@
+package invoker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithCompression(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	body := json.RawMessage(`{"key":"` + strings.Repeat("a", 4096) + `"}`)
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.Less(t, len(i.Payload), len(body))
+		payload, err := decompress(i.Payload)
+		require.NoError(t, err)
+		assert.Equal(t, string(body), string(payload))
+		// Echo the compressed payload back.
+		return &lambda.InvokeOutput{
+			Payload: i.Payload,
+		}, nil
+	})
+	invoker := New(li, arn, WithCompression())
+	result, err := invoker.Invoke(ctx, body)
+	require.NoError(t, err)
+	assert.Equal(t, string(body), string(result))
+}
+
+func TestInvokeWithCompressionSmallPayload(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	body := json.RawMessage(`{"key":"value"}`)
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.Equal(t, string(body), string(i.Payload))
+		return &lambda.InvokeOutput{
+			Payload: i.Payload,
+		}, nil
+	})
+	invoker := New(li, arn, WithCompression())
+	result, err := invoker.Invoke(ctx, body)
+	require.NoError(t, err)
+	assert.Equal(t, string(body), string(result))
+}
+
+func TestInvokeWithResponseDecompression(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	result := `{"body":"` + strings.Repeat("a", 4096) + `"}`
+	gzipped := &bytes.Buffer{}
+	w := gzip.NewWriter(gzipped)
+	_, err := w.Write([]byte(result))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	enveloped, err := compress([]byte(result))
+	require.NoError(t, err)
+	var payload []byte
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.Equal(t, `{"procedure":"Do","body":"small"}`, string(i.Payload))
+		return &lambda.InvokeOutput{
+			Payload: payload,
+		}, nil
+	})
+	invoker := New(li, arn, AsProcedure("Do", nil), WithResponseDecompression())
+	for _, p := range [][]byte{gzipped.Bytes(), enveloped, []byte(result)} {
+		payload = p
+		rsp, err := invoker.Invoke(ctx, json.RawMessage(`"small"`))
+		require.NoError(t, err)
+		assert.Equal(t, `"`+strings.Repeat("a", 4096)+`"`, string(rsp))
+	}
+
+	payload = append([]byte{}, gzipMagic...)
+	_, err = invoker.Invoke(ctx, json.RawMessage(`"small"`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decompress payload")
+}
@@ -0,0 +1,47 @@
+package invoker
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// NewFromConfig initializes an Invoker with a lambda client built from cfg,
+// for callers who don't already have one. If arn is a full ARN the client is
+// configured for the function's region, regardless of the region in cfg. The
+// arn is validated as with NewValidated.
+func NewFromConfig(cfg *aws.Config, arn string, opts ...Option) (*Invoker, error) {
+	function, err := ParseFunctionARN(arn)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = aws.NewConfig()
+	}
+	cfg = cfg.Copy()
+	if function.Region != "" {
+		cfg.Region = aws.String(function.Region)
+	}
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewValidated(lambda.New(sess), arn, opts...)
+}
+
+// NewFromEndpoint initializes an Invoker in the same way as NewFromConfig, but
+// with the lambda client configured to make requests to endpoint rather than
+// the default endpoint for the region; for example 'http://localhost:4566' to
+// invoke functions deployed to LocalStack in tests. Any endpoint set in cfg is
+// overridden.
+func NewFromEndpoint(endpoint string, cfg *aws.Config, arn string, opts ...Option) (*Invoker, error) {
+	if endpoint == "" {
+		return nil, errors.New("endpoint can't be empty")
+	}
+	if cfg == nil {
+		cfg = aws.NewConfig()
+	}
+	return NewFromConfig(cfg.Copy().WithEndpoint(endpoint), arn, opts...)
+}
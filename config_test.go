@@ -0,0 +1,31 @@
+package invoker
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	t.Parallel()
+	invoker, err := NewFromConfig(aws.NewConfig().WithRegion("us-east-1"), "arn:aws:lambda:eu-west-1:123456789012:function:my-function")
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", invoker.Function().Region)
+
+	_, err = NewFromConfig(nil, "not a function")
+	assert.Error(t, err)
+}
+
+func TestNewFromEndpoint(t *testing.T) {
+	t.Parallel()
+	cfg := aws.NewConfig().WithRegion("us-east-1")
+	invoker, err := NewFromEndpoint("http://localhost:4566", cfg, "my-function")
+	require.NoError(t, err)
+	assert.Equal(t, "my-function", invoker.ARN())
+	assert.Nil(t, cfg.Endpoint)
+
+	_, err = NewFromEndpoint("", cfg, "my-function")
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package invoker
+
+import "context"
+
+// invocationKey is the context key the invocation is carried under.
+type invocationKey struct{}
+
+// invocation identifies the lambda function, and procedure, being invoked.
+type invocation struct {
+	arn       string
+	procedure string
+}
+
+// withInvocation returns a copy of ctx carrying the ARN and procedure being
+// invoked by i. The procedure is the one passed to InvokeProcedure, if ctx
+// carries one, otherwise the one passed to AsProcedure.
+func (i *Invoker) withInvocation(ctx context.Context) context.Context {
+	procedure := i.procedure
+	if p, ok := i.procedureOverride(ctx); ok && procedure != "" {
+		procedure = p
+	}
+	return context.WithValue(ctx, invocationKey{}, invocation{
+		arn:       i.arn,
+		procedure: procedure,
+	})
+}
+
+// ARNFromContext returns the name or ARN of the lambda function being
+// invoked, as passed when the Invoker was initialized; ctx must be one passed
+// by Invoke, or one of its variants, to Middleware or another hook such as a
+// mutator or tag extractor. It returns false if ctx doesn't carry one.
+func ARNFromContext(ctx context.Context) (string, bool) {
+	inv, ok := ctx.Value(invocationKey{}).(invocation)
+	return inv.arn, ok
+}
+
+// ProcedureFromContext returns the procedure being called by an Invoker
+// initialized with AsProcedure, from a ctx passed to a hook as described by
+// ARNFromContext. It returns false if ctx doesn't carry one.
+func ProcedureFromContext(ctx context.Context) (string, bool) {
+	inv, ok := ctx.Value(invocationKey{}).(invocation)
+	return inv.procedure, ok && inv.procedure != ""
+}
@@ -0,0 +1,49 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestARNFromContext(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{}`),
+		}, nil
+	})
+	type annotation struct {
+		arn, procedure string
+	}
+	var annotations []annotation
+	annotate := func(next InvokeFunc) InvokeFunc {
+		return func(ctx context.Context, body json.RawMessage) (json.RawMessage, error) {
+			arn, ok := ARNFromContext(ctx)
+			require.True(t, ok)
+			procedure, _ := ProcedureFromContext(ctx)
+			annotations = append(annotations, annotation{arn, procedure})
+			return next(ctx, body)
+		}
+	}
+	_, err := New(li, arn, WithMiddleware(annotate)).Invoke(ctx, nil)
+	require.NoError(t, err)
+	invoker := New(li, arn, AsProcedure("Get", nil), WithMiddleware(annotate))
+	_, err = invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	_, err = invoker.InvokeProcedure(ctx, "Put", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []annotation{{arn, ""}, {arn, "Get"}, {arn, "Put"}}, annotations)
+
+	_, ok := ARNFromContext(ctx)
+	assert.False(t, ok)
+	_, ok = ProcedureFromContext(ctx)
+	assert.False(t, ok)
+}
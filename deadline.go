@@ -0,0 +1,63 @@
+package invoker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInsufficientTime is returned by Invoke when WithDeadlineBuffer or
+// WithDeadlineFraction is provided and the context passed doesn't leave
+// enough time before its deadline to invoke the lambda function.
+var ErrInsufficientTime = errors.New("not enough time remaining before the deadline to invoke")
+
+// WithDeadlineBuffer returns an option which can be passed when initializing
+// an Invoker. If provided, and the context passed to Invoke has a deadline,
+// the invocation must complete at least buffer before that deadline. This is
+// useful when invoking from within a lambda function, whose context has a
+// deadline of when the function will be timed out; reserving a buffer leaves
+// time to clean up rather than the downstream invocation being orphaned.
+// If the deadline is less than buffer away ErrInsufficientTime is returned
+// without the lambda function being invoked.
+func WithDeadlineBuffer(buffer time.Duration) Option {
+	return func(i *Invoker) {
+		i.deadlineBuffer = buffer
+	}
+}
+
+// WithDeadlineFraction returns an option which can be passed when
+// initializing an Invoker. If provided, and the context passed to Invoke has
+// a deadline, the invocation must complete within fraction of the time
+// remaining before that deadline, once any buffer reserved by
+// WithDeadlineBuffer has been taken off; so the downstream function's timeout
+// is proportional to the time the caller has left. For example a fraction of
+// 0.5 gives an invocation made with 20s remaining, and a 2s buffer, 9s to
+// complete. Fractions outside (0, 1] are ignored.
+func WithDeadlineFraction(fraction float64) Option {
+	return func(i *Invoker) {
+		if fraction <= 0 || fraction > 1 {
+			return
+		}
+		i.deadlineFraction = fraction
+	}
+}
+
+// reserveDeadline returns a context whose deadline leaves the buffer before
+// the deadline of ctx, if it has one, and is shortened to the Invoker's
+// fraction of the time which remains.
+func (i *Invoker) reserveDeadline(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	deadline, ok := ctx.Deadline()
+	if (i.deadlineBuffer <= 0 && i.deadlineFraction == 0) || !ok {
+		return ctx, func() {}, nil
+	}
+	now := time.Now()
+	deadline = deadline.Add(-i.deadlineBuffer)
+	if !now.Before(deadline) {
+		return nil, nil, ErrInsufficientTime
+	}
+	if i.deadlineFraction > 0 {
+		deadline = now.Add(time.Duration(float64(deadline.Sub(now)) * i.deadlineFraction))
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	return ctx, cancel, nil
+}
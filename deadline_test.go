@@ -0,0 +1,77 @@
+package invoker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithDeadlineBuffer(t *testing.T) {
+	t.Parallel()
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(ctx context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		d, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.Equal(t, deadline.Add(-10*time.Second), d)
+		return &lambda.InvokeOutput{}, nil
+	})
+	_, err := New(li, arn, WithDeadlineBuffer(10*time.Second)).Invoke(ctx, nil)
+	require.NoError(t, err)
+}
+
+func TestInvokeWithDeadlineBufferInsufficientTime(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda function shouldn't be invoked")
+		return nil, nil
+	})
+	_, err := New(li, arn, WithDeadlineBuffer(10*time.Second)).Invoke(ctx, nil)
+	assert.Equal(t, ErrInsufficientTime, err)
+}
+
+func TestInvokeWithDeadlineBufferNoDeadline(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(ctx context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+		return &lambda.InvokeOutput{}, nil
+	})
+	_, err := New(li, arn, WithDeadlineBuffer(10*time.Second)).Invoke(ctx, nil)
+	require.NoError(t, err)
+}
+
+func TestInvokeWithDeadlineFraction(t *testing.T) {
+	t.Parallel()
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	arn := "test-arn"
+	var remaining time.Duration
+	li := LambdaInvokerFunc(func(ctx context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		d, ok := ctx.Deadline()
+		require.True(t, ok)
+		remaining = time.Until(d)
+		return &lambda.InvokeOutput{}, nil
+	})
+	_, err := New(li, arn, WithDeadlineBuffer(20*time.Second), WithDeadlineFraction(0.5)).Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, float64(20*time.Second), float64(remaining), float64(time.Second))
+
+	// Fractions outside (0, 1] are ignored.
+	_, err = New(li, arn, WithDeadlineFraction(2)).Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, float64(time.Until(deadline)), float64(remaining), float64(time.Second))
+}
@@ -0,0 +1,29 @@
+package invoker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// WithResponseDecoder returns an option which can be passed when initializing
+// an Invoker. If provided, decode will be called with the payload returned by
+// the lambda function before any output mutators, including AsProcedure's, and
+// its result used in its place. It isn't called for empty payloads, or if the
+// function returned an error.
+func WithResponseDecoder(decode func([]byte) ([]byte, error)) Option {
+	return func(i *Invoker) {
+		i.responseDecoder = decode
+	}
+}
+
+// DecodeBase64JSON decodes a payload which is a JSON string holding base64
+// encoded data, as returned by some legacy lambda functions; it can be passed
+// to WithResponseDecoder.
+func DecodeBase64JSON(payload []byte) ([]byte, error) {
+	var encoded string
+	if err := json.Unmarshal(payload, &encoded); err != nil {
+		return nil, fmt.Errorf("payload isn't a JSON string: %w", err)
+	}
+	return base64.StdEncoding.DecodeString(encoded)
+}
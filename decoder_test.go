@@ -0,0 +1,58 @@
+package invoker
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithResponseDecoder(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	encoded := base64.StdEncoding.EncodeToString([]byte(`{"body":"result"}`))
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: []byte(`"` + encoded + `"`),
+		}, nil
+	})
+	invoker := New(li, arn, WithResponseDecoder(DecodeBase64JSON), AsProcedure("Do", nil))
+	result, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `"result"`, string(result))
+}
+
+func TestInvokeWithResponseDecoderFunctionError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       []byte(`{"errorMessage":"failed"}`),
+		}, nil
+	})
+	invoker := New(li, arn, WithResponseDecoder(func([]byte) ([]byte, error) {
+		t.Fatal("decoder should not be called")
+		return nil, nil
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.Equal(t, "failed", err.Error())
+}
+
+func TestDecodeBase64JSON(t *testing.T) {
+	t.Parallel()
+	decoded, err := DecodeBase64JSON([]byte(`"eyJhIjoxfQ=="`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(decoded))
+
+	_, err = DecodeBase64JSON([]byte(`{"a":1}`))
+	assert.Error(t, err)
+}
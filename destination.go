@@ -0,0 +1,58 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// InvokeEvent invokes the lambda function as an 'Event', returning the id aws
+// assigned to the request once the invocation has been accepted. When the
+// function has destinations configured the same id is delivered as the
+// RequestID of the DestinationRecord, so it can be used to correlate the
+// eventual outcome with this invocation.
+func (i *Invoker) InvokeEvent(ctx context.Context, body json.RawMessage, opts ...CallOption) (string, error) {
+	call := &callConfig{}
+	for _, opt := range opts {
+		opt(call)
+	}
+	call.invocationType = lambda.InvocationTypeEvent
+	_, meta, err := i.call(ctx, body, call)
+	return meta.RequestID, err
+}
+
+// DestinationRecord is the record lambda delivers to the destinations of a
+// function once an asynchronous invocation has succeeded or failed.
+type DestinationRecord struct {
+	Version         string                     `json:"version"`
+	Timestamp       string                     `json:"timestamp"`
+	RequestContext  DestinationRequestContext  `json:"requestContext"`
+	RequestPayload  json.RawMessage            `json:"requestPayload"`
+	ResponseContext DestinationResponseContext `json:"responseContext"`
+	ResponsePayload json.RawMessage            `json:"responsePayload"`
+}
+
+// DestinationRequestContext describes the asynchronous invocation a
+// DestinationRecord was delivered for. RequestID matches the id returned by
+// InvokeEvent.
+type DestinationRequestContext struct {
+	RequestID              string `json:"requestId"`
+	FunctionARN            string `json:"functionArn"`
+	Condition              string `json:"condition"`
+	ApproximateInvokeCount int    `json:"approximateInvokeCount"`
+}
+
+// DestinationResponseContext describes the response of the function to an
+// asynchronous invocation.
+type DestinationResponseContext struct {
+	StatusCode      int64  `json:"statusCode"`
+	ExecutedVersion string `json:"executedVersion"`
+	FunctionError   string `json:"functionError"`
+}
+
+// Succeeded returns whether the invocation the record was delivered for
+// succeeded.
+func (r DestinationRecord) Succeeded() bool {
+	return r.RequestContext.Condition == "Success"
+}
@@ -0,0 +1,69 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeEvent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, opts ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.Equal(t, lambda.InvocationTypeEvent, *input.InvocationType)
+		r := &awsreq.Request{}
+		for _, opt := range opts {
+			opt(r)
+		}
+		r.RequestID = "request-id"
+		r.Handlers.Complete.Run(r)
+		return &lambda.InvokeOutput{}, nil
+	})
+	requestID, err := New(li, arn).InvokeEvent(ctx, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, "request-id", requestID)
+}
+
+func TestInvokeEventAsProcedure(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda function shouldn't be invoked")
+		return nil, nil
+	})
+	_, err := New(li, arn, AsProcedure("Do", nil)).InvokeEvent(ctx, []byte(`{}`))
+	assert.Error(t, err)
+}
+
+func TestDestinationRecord(t *testing.T) {
+	t.Parallel()
+	record := DestinationRecord{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"version": "1.0",
+		"timestamp": "2019-11-14T18:16:05.568Z",
+		"requestContext": {
+			"requestId": "request-id",
+			"functionArn": "arn:aws:lambda:us-east-2:123456789012:function:my-function:$LATEST",
+			"condition": "RetriesExhausted",
+			"approximateInvokeCount": 3
+		},
+		"requestPayload": {"ORDER_IDS": ["9e07af03"]},
+		"responseContext": {
+			"statusCode": 200,
+			"executedVersion": "$LATEST",
+			"functionError": "Unhandled"
+		},
+		"responsePayload": {"errorMessage": "RequestId: request-id Process exited before completing request"}
+	}`), &record))
+	assert.Equal(t, "request-id", record.RequestContext.RequestID)
+	assert.Equal(t, 3, record.RequestContext.ApproximateInvokeCount)
+	assert.Equal(t, "Unhandled", record.ResponseContext.FunctionError)
+	assert.False(t, record.Succeeded())
+}
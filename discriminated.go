@@ -0,0 +1,48 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMissingDiscriminator is returned by InvokeDiscriminated if the
+	// response doesn't have the discriminator field, or it isn't a string.
+	ErrMissingDiscriminator = errors.New("response is missing its discriminator")
+	// ErrUnknownDiscriminator is returned by InvokeDiscriminated if the
+	// response's discriminator doesn't match any of the targets.
+	ErrUnknownDiscriminator = errors.New("response has an unknown discriminator")
+)
+
+// InvokeDiscriminated invokes the lambda function in the same way as Invoke,
+// for functions whose response is polymorphic: the response must be a JSON
+// object whose string field named field chooses its shape. The target
+// registered for that value in targets is called, and the response
+// unmarshaled into the value it returns, which should be a pointer; that
+// value is returned. Errors returned by Invoke are returned untouched.
+func (i *Invoker) InvokeDiscriminated(ctx context.Context, body json.RawMessage, field string, targets map[string]func() interface{}) (interface{}, error) {
+	result, err := i.Invoke(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(result, &fields); err != nil {
+		return nil, fmt.Errorf("unmarshaling discriminated response: %w", err)
+	}
+	var discriminator string
+	raw, ok := fields[field]
+	if !ok || json.Unmarshal(raw, &discriminator) != nil {
+		return nil, fmt.Errorf("%w: '%s'", ErrMissingDiscriminator, field)
+	}
+	target, ok := targets[discriminator]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s' is '%s'", ErrUnknownDiscriminator, field, discriminator)
+	}
+	v := target()
+	if err := json.Unmarshal(result, v); err != nil {
+		return nil, fmt.Errorf("unmarshaling '%s' response: %w", discriminator, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,58 @@
+package invoker
+
+import (
+	"context"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type circle struct {
+	Radius float64 `json:"radius"`
+}
+
+type square struct {
+	Side float64 `json:"side"`
+}
+
+var shapes = map[string]func() interface{}{
+	"circle": func() interface{} { return &circle{} },
+	"square": func() interface{} { return &square{} },
+}
+
+func TestInvokeDiscriminated(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var payload []byte
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: payload,
+		}, nil
+	})
+	invoker := New(li, arn)
+
+	payload = []byte(`{"type":"circle","radius":2}`)
+	shape, err := invoker.InvokeDiscriminated(ctx, nil, "type", shapes)
+	require.NoError(t, err)
+	assert.Equal(t, &circle{Radius: 2}, shape)
+
+	payload = []byte(`{"type":"square","side":3}`)
+	shape, err = invoker.InvokeDiscriminated(ctx, nil, "type", shapes)
+	require.NoError(t, err)
+	assert.Equal(t, &square{Side: 3}, shape)
+
+	for _, p := range []string{`{"radius":2}`, `{"type":1}`} {
+		payload = []byte(p)
+		_, err = invoker.InvokeDiscriminated(ctx, nil, "type", shapes)
+		assert.ErrorIs(t, err, ErrMissingDiscriminator)
+	}
+
+	payload = []byte(`{"type":"triangle"}`)
+	_, err = invoker.InvokeDiscriminated(ctx, nil, "type", shapes)
+	assert.ErrorIs(t, err, ErrUnknownDiscriminator)
+	assert.Contains(t, err.Error(), "triangle")
+}
@@ -0,0 +1,96 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// WithEnvelope returns an option which can be passed when initializing an
+// Invoker. If provided, the payload of each invocation will be passed to wrap
+// and the result sent in its place, and the payload returned by the lambda
+// function passed to unwrap before it's returned; in the same way as
+// AsProcedure, but for any envelope. Either may be nil. unwrap isn't called
+// for empty payloads, or if the function returned an error. It's applied as an
+// input and output mutator so composes with other Options; since output
+// mutators are called in reverse order, envelopes passed later wrap those
+// passed earlier.
+func WithEnvelope(wrap, unwrap func(json.RawMessage) (json.RawMessage, error)) Option {
+	return func(i *Invoker) {
+		if wrap != nil {
+			i.inputMutators = append(i.inputMutators, func(_ context.Context, input *lambda.InvokeInput) error {
+				payload, err := wrap(input.Payload)
+				if err != nil {
+					return err
+				}
+				input.Payload = payload
+				return nil
+			})
+		}
+		if unwrap != nil {
+			i.outputMutators = append(i.outputMutators, func(output *lambda.InvokeOutput) error {
+				if len(output.Payload) == 0 || output.FunctionError != nil {
+					return nil
+				}
+				payload, err := unwrap(output.Payload)
+				if err != nil {
+					return err
+				}
+				output.Payload = payload
+				return nil
+			})
+		}
+	}
+}
+
+// PayloadTransformer implementations transform the payload sent to a lambda
+// function, and symmetrically the payload it returns; for example to add
+// metadata, or migrate between schema versions.
+type PayloadTransformer interface {
+	// Encode transforms the payload sent to the lambda function.
+	Encode(json.RawMessage) (json.RawMessage, error)
+	// Decode reverses the transformation on the payload returned by the
+	// lambda function.
+	Decode(json.RawMessage) (json.RawMessage, error)
+}
+
+// PayloadTransformerFuncs adapts a pair of funcs to the PayloadTransformer
+// interface; either may be nil, in which case the payload is left untouched.
+type PayloadTransformerFuncs struct {
+	EncodeFunc func(json.RawMessage) (json.RawMessage, error)
+	DecodeFunc func(json.RawMessage) (json.RawMessage, error)
+}
+
+// Encode calls f.EncodeFunc, if it isn't nil.
+func (f PayloadTransformerFuncs) Encode(payload json.RawMessage) (json.RawMessage, error) {
+	if f.EncodeFunc == nil {
+		return payload, nil
+	}
+	return f.EncodeFunc(payload)
+}
+
+// Decode calls f.DecodeFunc, if it isn't nil.
+func (f PayloadTransformerFuncs) Decode(payload json.RawMessage) (json.RawMessage, error) {
+	if f.DecodeFunc == nil {
+		return payload, nil
+	}
+	return f.DecodeFunc(payload)
+}
+
+// WithTransformers returns an option which can be passed when initializing
+// an Invoker. If provided, the payload of each invocation is encoded by each
+// of transformers in turn, and the payload returned by the lambda function
+// decoded by each of them in reverse order; each transformer is applied as an
+// envelope, as by WithEnvelope, so they compose with other Options in the
+// order they're passed. For example transformers passed before AsProcedure
+// transform the body of the procedure call, while those passed after it
+// transform the router.Request, treating the procedure envelope as one stage
+// of the pipeline.
+func WithTransformers(transformers ...PayloadTransformer) Option {
+	return func(i *Invoker) {
+		for _, t := range transformers {
+			WithEnvelope(t.Encode, t.Decode)(i)
+		}
+	}
+}
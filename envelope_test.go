@@ -0,0 +1,123 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versionedEnvelope struct {
+	Version string          `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func wrapVersioned(body json.RawMessage) (json.RawMessage, error) {
+	return json.Marshal(versionedEnvelope{Version: "1", Data: body})
+}
+
+func unwrapVersioned(payload json.RawMessage) (json.RawMessage, error) {
+	envelope := versionedEnvelope{}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Version != "1" {
+		return nil, errors.New("unsupported version")
+	}
+	return envelope.Data, nil
+}
+
+func TestInvokeWithEnvelope(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.JSONEq(t, `{"version":"1","data":{"key":"value"}}`, string(i.Payload))
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"version":"1","data":"result"}`),
+		}, nil
+	})
+	result, err := New(li, arn, WithEnvelope(wrapVersioned, unwrapVersioned)).Invoke(ctx, json.RawMessage(`{"key":"value"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `"result"`, string(result))
+}
+
+func TestInvokeWithEnvelopeAsProcedure(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.JSONEq(t, `{"version":"1","data":{"procedure":"Do","body":"content"}}`, string(i.Payload))
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"version":"1","data":{"body":"result"}}`),
+		}, nil
+	})
+	invoker := New(li, arn, AsProcedure("Do", nil), WithEnvelope(wrapVersioned, unwrapVersioned))
+	result, err := invoker.Invoke(ctx, json.RawMessage(`"content"`))
+	require.NoError(t, err)
+	assert.Equal(t, `"result"`, string(result))
+}
+
+func TestInvokeWithEnvelopeUnwrapError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"version":"2","data":"result"}`),
+		}, nil
+	})
+	_, err := New(li, arn, WithEnvelope(nil, unwrapVersioned)).Invoke(ctx, nil)
+	assert.EqualError(t, err, "unsupported version")
+}
+
+// tagTransformer wraps payloads in an object keyed on tag.
+type tagTransformer string
+
+func (t tagTransformer) Encode(payload json.RawMessage) (json.RawMessage, error) {
+	return json.Marshal(map[string]json.RawMessage{string(t): payload})
+}
+
+func (t tagTransformer) Decode(payload json.RawMessage) (json.RawMessage, error) {
+	wrapped := map[string]json.RawMessage{}
+	if err := json.Unmarshal(payload, &wrapped); err != nil {
+		return nil, err
+	}
+	return wrapped[string(t)], nil
+}
+
+func TestInvokeWithTransformers(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.JSONEq(t, `{"outer":{"version":"1","data":{"procedure":"Do","body":{"inner":"content"}}}}`, string(i.Payload))
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"outer":{"version":"1","data":{"body":{"inner":"result"}}}}`),
+		}, nil
+	})
+	invoker := New(li, arn,
+		WithTransformers(tagTransformer("inner")),
+		AsProcedure("Do", nil),
+		WithTransformers(PayloadTransformerFuncs{EncodeFunc: wrapVersioned, DecodeFunc: unwrapVersioned}, tagTransformer("outer")),
+	)
+	result, err := invoker.Invoke(ctx, json.RawMessage(`"content"`))
+	require.NoError(t, err)
+	assert.Equal(t, `"result"`, string(result))
+}
+
+func TestPayloadTransformerFuncs(t *testing.T) {
+	t.Parallel()
+	payload := json.RawMessage(`{}`)
+	encoded, err := PayloadTransformerFuncs{}.Encode(payload)
+	require.NoError(t, err)
+	assert.Equal(t, payload, encoded)
+	decoded, err := PayloadTransformerFuncs{}.Decode(payload)
+	require.NoError(t, err)
+	assert.Equal(t, payload, decoded)
+}
@@ -0,0 +1,290 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+var (
+	// ErrFunctionNotFound matches, using errors.Is, the error returned by
+	// Invoke when the lambda function doesn't exist.
+	ErrFunctionNotFound = errors.New("lambda function not found")
+	// ErrAccessDenied matches, using errors.Is, the error returned by Invoke
+	// when the caller isn't permitted to invoke the lambda function.
+	ErrAccessDenied = errors.New("access denied invoking lambda function")
+)
+
+// errCodeAccessDenied is the code of the error aws returns when the caller
+// isn't authorized to make a request.
+const errCodeAccessDenied = "AccessDeniedException"
+
+// Error wraps an error message with a status code.
+type Error struct {
+	error
+	StatusCode int64
+	// Payload is the raw payload returned by the lambda function, if any.
+	Payload    json.RawMessage
+	errorType  string
+	stackTrace []string
+	// functionError is the FunctionError of the InvokeOutput the Error was
+	// built from, if any.
+	functionError string
+}
+
+// Unwrap returns the error wrapped by e; for function errors this is the
+// message reported by the lambda function.
+func (e *Error) Unwrap() error {
+	return e.error
+}
+
+// As allows a function error to be retrieved as an InvocationError of
+// KindFunction with errors.As, so every failure to invoke the function can be
+// classified in the same way.
+func (e *Error) As(target interface{}) bool {
+	ierr, ok := target.(**InvocationError)
+	if !ok || e.functionError == "" {
+		return false
+	}
+	*ierr = &InvocationError{
+		Kind: KindFunction,
+		Err:  e,
+	}
+	return true
+}
+
+// Type returns the type of error reported by the lambda function runtime, if
+// known.
+func (e *Error) Type() string {
+	return e.errorType
+}
+
+// StackTrace returns the stack trace reported by the lambda function runtime,
+// if known.
+func (e *Error) StackTrace() []string {
+	return e.stackTrace
+}
+
+// ProcedureError is the error returned by an Invoker initialized with
+// AsProcedure when the procedure fails and no unmarshalError was provided. It
+// carries the error reported by the procedure as raw JSON, so structured
+// errors can still be decoded by the caller.
+type ProcedureError struct {
+	Raw json.RawMessage
+}
+
+// Error returns the raw JSON error reported by the procedure.
+func (e *ProcedureError) Error() string {
+	return string(e.Raw)
+}
+
+// Unmarshal decodes the error reported by the procedure into v.
+func (e *ProcedureError) Unmarshal(v interface{}) error {
+	return json.Unmarshal(e.Raw, v)
+}
+
+// PartialResultError wraps the error returned by a procedure called by
+// AsProcedure when its response also has a body; for example partial results
+// of a batch. Use errors.As to retrieve it.
+type PartialResultError struct {
+	error
+	// Body is the body of the procedure's response.
+	Body json.RawMessage
+}
+
+// Unwrap returns the error returned by the procedure.
+func (e *PartialResultError) Unwrap() error {
+	return e.error
+}
+
+// ErrorKind classifies why an invocation failed.
+type ErrorKind int
+
+const (
+	// KindTransport means the call to lambda failed; for example because of
+	// a network error, or the caller isn't permitted to invoke the function.
+	KindTransport ErrorKind = iota
+	// KindThrottled means lambda rejected the invocation because too many
+	// requests were being made.
+	KindThrottled
+	// KindFunction means the lambda function was invoked, and returned an
+	// error; Invoke returns the *Error itself, which errors.As converts to an
+	// InvocationError wrapping it.
+	KindFunction
+	// KindTimeout means the invocation didn't complete before the context's
+	// deadline; the InvocationError wraps context.DeadlineExceeded.
+	KindTimeout
+	// KindCancelled means the context was cancelled before the invocation
+	// completed; the InvocationError wraps context.Canceled.
+	KindCancelled
+)
+
+// String returns the name of the kind.
+func (k ErrorKind) String() string {
+	switch k {
+	case KindTransport:
+		return "transport"
+	case KindThrottled:
+		return "throttled"
+	case KindFunction:
+		return "function"
+	case KindTimeout:
+		return "timeout"
+	case KindCancelled:
+		return "cancelled"
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// InvocationError is returned by Invoke when invoking the lambda function
+// fails, wrapping the underlying error with the kind of failure; use
+// errors.As to retrieve it. Function errors are returned as an *Error, which
+// errors.As also converts to an InvocationError. Errors returned by mutators
+// or by the procedure called by AsProcedure aren't wrapped.
+type InvocationError struct {
+	Kind ErrorKind
+	Err  error
+}
+
+// Error returns the message of the wrapped error.
+func (e *InvocationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *InvocationError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether e is the failure described by target; this allows
+// ErrFunctionNotFound and ErrAccessDenied to be matched with errors.Is, while
+// the aws error remains reachable with Unwrap.
+func (e *InvocationError) Is(target error) bool {
+	var aerr awserr.Error
+	if !errors.As(e.Err, &aerr) {
+		return false
+	}
+	switch target {
+	case ErrFunctionNotFound:
+		return aerr.Code() == lambda.ErrCodeResourceNotFoundException
+	case ErrAccessDenied:
+		return aerr.Code() == errCodeAccessDenied
+	}
+	return false
+}
+
+// newInvocationError wraps err, returned by the LambdaInvoker, with the kind
+// of failure it represents.
+func newInvocationError(err error) error {
+	return &InvocationError{
+		Kind: errorKind(err),
+		Err:  err,
+	}
+}
+
+// errorKind returns the kind of failure err, returned by the LambdaInvoker,
+// represents.
+func errorKind(err error) ErrorKind {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return KindCancelled
+	}
+	if awsreq.IsErrorThrottle(err) {
+		return KindThrottled
+	}
+	if rerr, ok := err.(awserr.RequestFailure); ok && rerr.StatusCode() == http.StatusTooManyRequests {
+		return KindThrottled
+	}
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == lambda.ErrCodeTooManyRequestsException {
+		return KindThrottled
+	}
+	return KindTransport
+}
+
+// functionError is the payload returned by the lambda runtime when a function
+// fails.
+type functionError struct {
+	ErrorMessage string          `json:"errorMessage"`
+	ErrorType    string          `json:"errorType"`
+	StackTrace   json.RawMessage `json:"stackTrace"`
+}
+
+// stackFrame is the format the Go runtime reports each line of a stack trace
+// in; other runtimes use plain strings.
+type stackFrame struct {
+	Path  string `json:"path"`
+	Line  int    `json:"line"`
+	Label string `json:"label"`
+}
+
+// WithErrorStatusMapper returns an option which can be passed when
+// initializing an Invoker. If provided, mapStatus will be called to derive the
+// StatusCode of the Error returned when the lambda function fails. By default
+// the status code of the invocation is used, or -1 if there isn't one.
+func WithErrorStatusMapper(mapStatus func(*lambda.InvokeOutput) int64) Option {
+	return func(i *Invoker) {
+		i.errorStatus = mapStatus
+	}
+}
+
+// defaultErrorStatus returns the status code of output, or -1 if it doesn't
+// have one.
+func defaultErrorStatus(output *lambda.InvokeOutput) int64 {
+	if output.StatusCode == nil {
+		return -1
+	}
+	return *output.StatusCode
+}
+
+// newFunctionError builds an Error from an InvokeOutput which has a
+// FunctionError set. If the payload describes the error its message, type and
+// stack trace are used, otherwise the FunctionError itself is the message.
+func (i *Invoker) newFunctionError(output *lambda.InvokeOutput) *Error {
+	return newFunctionError(output, i.errorStatus(output))
+}
+
+func newFunctionError(output *lambda.InvokeOutput, statusCode int64) *Error {
+	e := &Error{
+		error:         errors.New(*output.FunctionError),
+		StatusCode:    statusCode,
+		Payload:       output.Payload,
+		functionError: *output.FunctionError,
+	}
+	payload := functionError{}
+	if err := json.Unmarshal(output.Payload, &payload); err != nil {
+		return e
+	}
+	if payload.ErrorMessage != "" {
+		e.error = errors.New(payload.ErrorMessage)
+	}
+	e.errorType = payload.ErrorType
+	e.stackTrace = parseStackTrace(payload.StackTrace)
+	return e
+}
+
+func parseStackTrace(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	lines := []string{}
+	if err := json.Unmarshal(raw, &lines); err == nil {
+		return lines
+	}
+	frames := []stackFrame{}
+	if err := json.Unmarshal(raw, &frames); err != nil {
+		return nil
+	}
+	lines = make([]string, 0, len(frames))
+	for _, frame := range frames {
+		lines = append(lines, fmt.Sprintf("%s (%s:%d)", frame.Label, frame.Path, frame.Line))
+	}
+	return lines
+}
@@ -0,0 +1,276 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithFunctionError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			StatusCode:    aws.Int64(http.StatusOK),
+			Payload:       json.RawMessage(`{"errorMessage":"boom","errorType":"TypeError","stackTrace":["at handler (index.js:1:1)"]}`),
+		}, nil
+	})
+	invoker := New(li, arn)
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "boom", e.Error())
+	assert.Equal(t, "TypeError", e.Type())
+	assert.Equal(t, []string{"at handler (index.js:1:1)"}, e.StackTrace())
+	assert.Equal(t, int64(http.StatusOK), e.StatusCode)
+}
+
+func TestInvokeWithGoFunctionError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       json.RawMessage(`{"errorMessage":"boom","errorType":"runtime.Error","stackTrace":[{"path":"main.go","line":10,"label":"main"}]}`),
+		}, nil
+	})
+	invoker := New(li, arn)
+	_, err := invoker.Invoke(ctx, nil)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "boom", e.Error())
+	assert.Equal(t, []string{"main (main.go:10)"}, e.StackTrace())
+}
+
+func TestInvokeWithFunctionErrorPayload(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	payload := json.RawMessage(`{"errorMessage":"invalid","detail":{"field":"name"}}`)
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Handled"),
+			Payload:       payload,
+		}, nil
+	})
+	invoker := New(li, arn)
+	_, err := invoker.Invoke(ctx, nil)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "invalid", e.Error())
+	assert.Equal(t, string(payload), string(e.Payload))
+}
+
+func TestInvokeWithErrorStatusMapper(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+		}, nil
+	})
+	invoker := New(li, arn, WithErrorStatusMapper(func(o *lambda.InvokeOutput) int64 {
+		assert.Equal(t, "Unhandled", *o.FunctionError)
+		return http.StatusInternalServerError
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, int64(http.StatusInternalServerError), e.StatusCode)
+}
+
+func TestInvokeWithErrorDefaultStatus(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+		}, nil
+	})
+	_, err := New(li, arn).Invoke(ctx, nil)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, int64(-1), e.StatusCode)
+}
+
+func TestInvokeInvocationErrorKind(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	for name, tc := range map[string]struct {
+		output *lambda.InvokeOutput
+		err    error
+		kind   ErrorKind
+	}{
+		"transport": {
+			err:  awserr.New("AccessDeniedException", "not authorized", nil),
+			kind: KindTransport,
+		},
+		"throttled": {
+			err:  awserr.New(lambda.ErrCodeTooManyRequestsException, "slow down", nil),
+			kind: KindThrottled,
+		},
+		"timeout": {
+			err:  context.DeadlineExceeded,
+			kind: KindTimeout,
+		},
+		"cancelled": {
+			err:  context.Canceled,
+			kind: KindCancelled,
+		},
+		"function": {
+			output: &lambda.InvokeOutput{FunctionError: aws.String("Unhandled")},
+			kind:   KindFunction,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+				return tc.output, tc.err
+			})
+			_, err := New(li, arn).Invoke(ctx, nil)
+			var e *InvocationError
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, tc.kind, e.Kind)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestInvokeFunctionError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       []byte(`{"errorMessage":"failed"}`),
+		}, nil
+	})
+	_, err := New(li, arn).Invoke(ctx, nil)
+	e, ok := err.(*Error)
+	require.True(t, ok)
+	var ierr *InvocationError
+	require.True(t, errors.As(err, &ierr))
+	assert.Equal(t, KindFunction, ierr.Kind)
+	assert.Equal(t, e, ierr.Err)
+}
+
+func TestErrorUnwrap(t *testing.T) {
+	t.Parallel()
+	cause := errors.New("failed")
+	err := error(&InvocationError{
+		Kind: KindFunction,
+		Err: &Error{
+			error:      cause,
+			StatusCode: http.StatusOK,
+		},
+	})
+	assert.True(t, errors.Is(err, cause))
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, cause, e.Unwrap())
+}
+
+func TestInvokeSentinelErrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	for _, tc := range []struct {
+		err      error
+		sentinel error
+	}{
+		{
+			err:      awserr.NewRequestFailure(awserr.New(lambda.ErrCodeResourceNotFoundException, "function not found", nil), http.StatusNotFound, "request-id"),
+			sentinel: ErrFunctionNotFound,
+		},
+		{
+			err:      awserr.NewRequestFailure(awserr.New("AccessDeniedException", "not authorized", nil), http.StatusForbidden, "request-id"),
+			sentinel: ErrAccessDenied,
+		},
+	} {
+		li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+			return nil, tc.err
+		})
+		_, err := New(li, arn).Invoke(ctx, nil)
+		assert.ErrorIs(t, err, tc.sentinel)
+		assert.ErrorIs(t, err, tc.err)
+		var aerr awserr.Error
+		require.True(t, errors.As(err, &aerr))
+	}
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return nil, awserr.New(lambda.ErrCodeServiceException, "unavailable", nil)
+	})
+	_, err := New(li, arn).Invoke(ctx, nil)
+	assert.False(t, errors.Is(err, ErrFunctionNotFound))
+	assert.False(t, errors.Is(err, ErrAccessDenied))
+}
+
+func TestInvokeAsProcedureDefaultError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"error":{"code":"not_found","fields":["id"]}}`),
+		}, nil
+	})
+	_, err := New(li, arn, AsProcedure("Do", nil)).Invoke(ctx, nil)
+	var e *ProcedureError
+	require.True(t, errors.As(err, &e))
+	assert.JSONEq(t, `{"code":"not_found","fields":["id"]}`, string(e.Raw))
+	assert.JSONEq(t, `{"code":"not_found","fields":["id"]}`, e.Error())
+	detail := struct {
+		Code   string   `json:"code"`
+		Fields []string `json:"fields"`
+	}{}
+	require.NoError(t, e.Unmarshal(&detail))
+	assert.Equal(t, "not_found", detail.Code)
+	assert.Equal(t, []string{"id"}, detail.Fields)
+}
+
+func TestInvokeAsProcedurePartialResult(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var payload []byte
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: payload,
+		}, nil
+	})
+	procedureErr := errors.New("partially failed")
+	invoker := New(li, arn, AsProcedure("Do", func(json.RawMessage) error {
+		return procedureErr
+	}))
+
+	payload = []byte(`{"body":{"processed":[1,2]},"error":"partially failed"}`)
+	_, err := invoker.Invoke(ctx, nil)
+	assert.ErrorIs(t, err, procedureErr)
+	var partial *PartialResultError
+	require.True(t, errors.As(err, &partial))
+	assert.JSONEq(t, `{"processed":[1,2]}`, string(partial.Body))
+	assert.Equal(t, "partially failed", err.Error())
+
+	payload = []byte(`{"body":null,"error":"failed"}`)
+	_, err = invoker.Invoke(ctx, nil)
+	assert.Equal(t, procedureErr, err)
+}
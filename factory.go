@@ -0,0 +1,29 @@
+package invoker
+
+// InvokerFactory initializes Invokers for different lambda functions with a
+// shared set of options, so cross-cutting configuration such as logging,
+// metrics and retries is applied consistently.
+type InvokerFactory struct {
+	li   LambdaInvoker
+	opts []Option
+}
+
+// NewInvokerFactory initializes an InvokerFactory which builds Invokers using
+// li, configured with opts.
+func NewInvokerFactory(li LambdaInvoker, opts ...Option) *InvokerFactory {
+	return &InvokerFactory{
+		li:   li,
+		opts: append([]Option(nil), opts...),
+	}
+}
+
+// For initializes an Invoker of the lambda function arn, as New would, with
+// the factory's options followed by extra. Each Invoker has its own state,
+// such as its circuit breaker and rate limiter; but values passed to options,
+// such as a Cache or Logger, are shared by every Invoker built with them.
+func (f *InvokerFactory) For(arn string, extra ...Option) *Invoker {
+	opts := make([]Option, 0, len(f.opts)+len(extra))
+	opts = append(opts, f.opts...)
+	opts = append(opts, extra...)
+	return New(f.li, arn, opts...)
+}
@@ -0,0 +1,43 @@
+package invoker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokerFactory(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		if *input.FunctionName == "failing" {
+			return &lambda.InvokeOutput{
+				FunctionError: aws.String("Unhandled"),
+			}, nil
+		}
+		return &lambda.InvokeOutput{}, nil
+	})
+	var logged []string
+	factory := NewInvokerFactory(li, WithLogger(LoggerFunc(func(l InvokeLog) {
+		logged = append(logged, l.ARN)
+	})), WithCircuitBreaker(BreakerConfig{
+		Threshold: 1,
+		Cooldown:  time.Minute,
+	}))
+	failing := factory.For("failing")
+	healthy := factory.For("healthy", WithQualifier("prod"))
+	assert.Equal(t, "failing", failing.ARN())
+	_, err := failing.Invoke(ctx, nil)
+	require.Error(t, err)
+	_, err = failing.Invoke(ctx, nil)
+	assert.Equal(t, ErrCircuitOpen, err)
+	_, err = healthy.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"failing", "healthy"}, logged)
+}
@@ -0,0 +1,67 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// WithFallback returns an option which can be passed when initializing an
+// Invoker. If provided, invocations which fail without the lambda function
+// returning an error, either because the call to lambda failed, the invocation
+// had a 5xx status code or the circuit breaker is open, will be retried with
+// fallback; passing the same body. Function errors don't cause the fallback
+// to be used since they're returned by the lambda function itself. The result
+// of the fallback is returned whether it succeeds or fails. If the fallback
+// was initialized with AsProcedure, invocations made by InvokeProcedure call
+// the same procedure on it.
+func WithFallback(fallback *Invoker) Option {
+	return func(i *Invoker) {
+		i.fallback = fallback
+	}
+}
+
+// WithDefaultOnError returns an option which can be passed when initializing
+// an Invoker. If provided, whenever Invoke would return an error, including a
+// function error, defaultOnError will be called with it; if it returns true
+// the payload it returns is returned in place of the error. Otherwise the
+// error is returned as usual. Errors due to the Invoker being misconfigured
+// aren't passed to it, and Stats still counts the invocation as a failure.
+// InvokeRaw doesn't use it, so function errors can be inspected.
+func WithDefaultOnError(defaultOnError func(err error) (json.RawMessage, bool)) Option {
+	return func(i *Invoker) {
+		i.defaultOnError = defaultOnError
+	}
+}
+
+// shouldFallback reports whether the outcome of an invocation means the
+// fallback should be invoked instead.
+func shouldFallback(output *lambda.InvokeOutput, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	if output == nil || output.FunctionError != nil {
+		return false
+	}
+	return aws.Int64Value(output.StatusCode) >= 500
+}
+
+// invokeFallback invokes the fallback Invoker, through its Middleware, as
+// configured by call. The procedure passed to InvokeProcedure is carried over
+// if the fallback calls a procedure too.
+func (i *Invoker) invokeFallback(ctx context.Context, body json.RawMessage, call *callConfig) (json.RawMessage, error) {
+	fallback := i.fallback
+	if fallback.err != nil {
+		return nil, fallback.err
+	}
+	if procedure, ok := i.procedureOverride(ctx); ok && fallback.procedure != "" {
+		ctx = context.WithValue(ctx, procedureKey{invoker: fallback}, procedure)
+	}
+	invoke := func(ctx context.Context, body json.RawMessage) (json.RawMessage, error) {
+		return fallback.invoke(ctx, body, call)
+	}
+	return fallback.middleware(invoke)(ctx, body)
+}
@@ -0,0 +1,122 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	router "github.com/edstell/lambda-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithFallback(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var fallbackBody string
+	primary := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return nil, awserr.NewRequestFailure(awserr.New(lambda.ErrCodeServiceException, "unavailable", nil), http.StatusServiceUnavailable, "request-id")
+	})
+	secondary := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.Equal(t, "secondary-arn", *input.FunctionName)
+		fallbackBody = string(input.Payload)
+		return &lambda.InvokeOutput{
+			Payload: []byte(`"fallback"`),
+		}, nil
+	})
+	invoker := New(primary, "primary-arn", WithFallback(New(secondary, "secondary-arn")))
+	result, meta, err := invoker.InvokeWithMeta(ctx, []byte(`"body"`))
+	require.NoError(t, err)
+	assert.Equal(t, `"fallback"`, string(result))
+	assert.Equal(t, `"body"`, fallbackBody)
+	assert.Equal(t, int64(0), meta.StatusCode)
+}
+
+func TestInvokeProcedureWithFallback(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	primary := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return nil, awserr.NewRequestFailure(awserr.New(lambda.ErrCodeServiceException, "unavailable", nil), http.StatusServiceUnavailable, "request-id")
+	})
+	secondary := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		req := router.Request{}
+		require.NoError(t, json.Unmarshal(input.Payload, &req))
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"body":"` + req.Procedure + `"}`),
+		}, nil
+	})
+	invoker := New(primary, "primary-arn", AsProcedure("Get", nil), WithFallback(New(secondary, "secondary-arn", AsProcedure("Get", nil))))
+	result, err := invoker.InvokeProcedure(ctx, "Put", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `"Put"`, string(result))
+}
+
+func TestInvokeWithFallbackFunctionError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	primary := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			StatusCode:    aws.Int64(http.StatusOK),
+		}, nil
+	})
+	secondary := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("fallback should not be invoked")
+		return nil, nil
+	})
+	invoker := New(primary, "primary-arn", WithFallback(New(secondary, "secondary-arn")))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	_, ok := err.(*Error)
+	assert.True(t, ok)
+}
+
+func TestInvokeWithFallbackFailed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	primary := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return nil, assert.AnError
+	})
+	secondary := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return nil, awserr.New(lambda.ErrCodeResourceNotFoundException, "not found", nil)
+	})
+	invoker := New(primary, "primary-arn", WithFallback(New(secondary, "secondary-arn")))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	var aerr awserr.Error
+	require.True(t, errors.As(err, &aerr))
+	assert.Equal(t, lambda.ErrCodeResourceNotFoundException, aerr.Code())
+}
+
+func TestInvokeWithDefaultOnError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var invokeErr error
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, invokeErr
+	})
+	unavailable := errors.New("unavailable")
+	invoker := New(li, arn, WithDefaultOnError(func(err error) (json.RawMessage, bool) {
+		if errors.Is(err, unavailable) {
+			return json.RawMessage(`{"default":true}`), true
+		}
+		return nil, false
+	}))
+
+	invokeErr = unavailable
+	result, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"default":true}`, string(result))
+	assert.Equal(t, int64(1), invoker.Stats().Failures)
+
+	invokeErr = errors.New("access denied")
+	_, err = invoker.Invoke(ctx, nil)
+	assert.ErrorIs(t, err, invokeErr)
+}
@@ -0,0 +1,80 @@
+package invoker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// FunctionURLInvoker is a LambdaInvoker which invokes a lambda function by
+// POSTing the payload to its function URL, rather than through the Invoke
+// API. Responses with a non-2xx status code are reported as a FunctionError,
+// so Invoke returns an Error with the status code; responses with a 429
+// status code are reported as throttling, so can be retried. Function URLs
+// only support synchronous invocation, and don't accept a Qualifier or
+// ClientContext, which are ignored. The aws sdk request options aren't used,
+// so if the function URL uses IAM auth the http.Client must sign requests.
+type FunctionURLInvoker struct {
+	url    string
+	client *http.Client
+}
+
+var _ LambdaInvoker = (*FunctionURLInvoker)(nil)
+
+// NewFunctionURLInvoker initializes a FunctionURLInvoker which POSTs to url
+// using client. If client is nil http.DefaultClient is used.
+func NewFunctionURLInvoker(url string, client *http.Client) *FunctionURLInvoker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &FunctionURLInvoker{
+		url:    url,
+		client: client,
+	}
+}
+
+// AppliesRequestOptions reports false; the aws sdk request options aren't
+// used to make the request.
+func (f *FunctionURLInvoker) AppliesRequestOptions() bool {
+	return false
+}
+
+// InvokeWithContext POSTs the input's payload to the function URL.
+func (f *FunctionURLInvoker) InvokeWithContext(ctx context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+	if t := aws.StringValue(input.InvocationType); t != "" && t != lambda.InvocationTypeRequestResponse {
+		return nil, fmt.Errorf("function urls can't be invoked as '%s'", t)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, bytes.NewReader(input.Payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	payload, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	requestID := rsp.Header.Get("X-Amzn-Requestid")
+	if rsp.StatusCode == http.StatusTooManyRequests {
+		return nil, awserr.NewRequestFailure(awserr.New(lambda.ErrCodeTooManyRequestsException, string(payload), nil), rsp.StatusCode, requestID)
+	}
+	output := &lambda.InvokeOutput{
+		StatusCode: aws.Int64(int64(rsp.StatusCode)),
+		Payload:    payload,
+	}
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		output.FunctionError = aws.String(http.StatusText(rsp.StatusCode))
+	}
+	return output, nil
+}
@@ -0,0 +1,72 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	router "github.com/edstell/lambda-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctionURLInvokerAsProcedure(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		req := router.Request{}
+		require.NoError(t, json.Unmarshal(body, &req))
+		assert.Equal(t, "Do", req.Procedure)
+		_, _ = w.Write([]byte(`{"body":` + string(req.Body) + `}`))
+	}))
+	defer server.Close()
+	li := NewFunctionURLInvoker(server.URL, server.Client())
+	invoker := New(li, server.URL, AsProcedure("Do", nil))
+	result, err := invoker.Invoke(ctx, []byte(`{"key":"value"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"key":"value"}`, string(result))
+}
+
+func TestFunctionURLInvokerError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(`{"errorMessage":"failed","errorType":"Error"}`))
+	}))
+	defer server.Close()
+	invoker := New(NewFunctionURLInvoker(server.URL, nil), server.URL)
+	_, err := invoker.Invoke(ctx, nil)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, int64(http.StatusBadGateway), e.StatusCode)
+	assert.Equal(t, "failed", e.Error())
+	assert.Equal(t, "Error", e.Type())
+}
+
+func TestFunctionURLInvokerThrottled(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`"ok"`))
+	}))
+	defer server.Close()
+	invoker := New(NewFunctionURLInvoker(server.URL, nil), server.URL, WithRetry(2, noBackoff))
+	result, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `"ok"`, string(result))
+	assert.Equal(t, 2, attempts)
+}
@@ -0,0 +1,36 @@
+package invoker
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// Healthcheck verifies the lambda function can be invoked, by invoking it as
+// a 'DryRun'; the function isn't executed. Input mutators are applied, as for
+// an empty body, so the same version and account are checked as by Invoke,
+// but no payload is sent, so procedures configured by AsProcedure aren't
+// called. Middleware, retries and the circuit breaker aren't used, so the
+// result reflects the current state of the function and the caller's
+// permissions; use errors.Is with ErrFunctionNotFound and ErrAccessDenied to
+// distinguish common failures.
+func (i *Invoker) Healthcheck(ctx context.Context) error {
+	if i.err != nil {
+		return i.err
+	}
+	input := &lambda.InvokeInput{
+		FunctionName:   aws.String(i.arn),
+		InvocationType: aws.String(lambda.InvocationTypeDryRun),
+		Payload:        i.normalizePayload(nil),
+	}
+	if err := i.mutateInput(ctx, input); err != nil {
+		return err
+	}
+	input.InvocationType = aws.String(lambda.InvocationTypeDryRun)
+	input.Payload = nil
+	if _, err := i.li.InvokeWithContext(ctx, input, i.requestOptions...); err != nil {
+		return newInvocationError(err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package invoker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthcheck(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var input *lambda.InvokeInput
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		input = i
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, AsProcedure("Do", nil), WithQualifier("prod"))
+	err := invoker.Healthcheck(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, input)
+	assert.Equal(t, lambda.InvocationTypeDryRun, *input.InvocationType)
+	assert.Equal(t, "prod", *input.Qualifier)
+	assert.Nil(t, input.Payload)
+}
+
+func TestHealthcheckFailed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return nil, awserr.New("AccessDeniedException", "not authorized", nil)
+	})
+	err := New(li, arn).Healthcheck(ctx)
+	assert.ErrorIs(t, err, ErrAccessDenied)
+
+	err = New(nil, arn).Healthcheck(ctx)
+	assert.Error(t, err)
+}
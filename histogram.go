@@ -0,0 +1,146 @@
+package invoker
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the buckets latencies are counted in;
+// latencies above the last are counted in an overflow bucket.
+var latencyBuckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+// InfiniteLatency is the UpperBound of the overflow bucket, which counts the
+// latencies greater than the UpperBound of every other bucket; like the +Inf
+// bucket of a Prometheus histogram, it's greater than any latency.
+const InfiniteLatency = time.Duration(math.MaxInt64)
+
+// LatencyBucket counts the latencies which were no greater than UpperBound,
+// and greater than the UpperBound of the previous bucket.
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      int64
+}
+
+// Latencies is a snapshot of the latencies of the attempts an Invoker has made
+// to invoke its lambda function.
+type Latencies struct {
+	ARN   string
+	Count int64
+	// Max is the greatest latency observed.
+	Max time.Duration
+	// Buckets hold the counts of latencies by size, in increasing order of
+	// UpperBound; the last bucket holds any latencies greater than a minute,
+	// its UpperBound is InfiniteLatency.
+	Buckets []LatencyBucket
+}
+
+// Percentile returns an estimate of the latency which p percent of attempts
+// completed within; it's the UpperBound of the bucket the nearest-rank
+// percentile falls in, or Max if it falls in the overflow bucket. Zero is
+// returned if no latencies have been observed.
+func (l Latencies) Percentile(p float64) time.Duration {
+	if l.Count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(p * float64(l.Count) / 100))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for _, bucket := range l.Buckets {
+		seen += bucket.Count
+		if seen >= rank {
+			if bucket.UpperBound == InfiniteLatency {
+				return l.Max
+			}
+			return bucket.UpperBound
+		}
+	}
+	return l.Max
+}
+
+// histogram counts latencies in fixed buckets.
+type histogram struct {
+	mu     sync.Mutex
+	counts []int64
+	count  int64
+	max    time.Duration
+}
+
+func newHistogram() *histogram {
+	return &histogram{
+		counts: make([]int64, len(latencyBuckets)+1),
+	}
+}
+
+// observe counts latency d.
+func (h *histogram) observe(d time.Duration) {
+	bucket := len(latencyBuckets)
+	for n, bound := range latencyBuckets {
+		if d <= bound {
+			bucket = n
+			break
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[bucket]++
+	h.count++
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// WithLatencyHistogram returns an option which can be passed when
+// initializing an Invoker. If provided the latency of each attempt to invoke
+// the lambda function will be counted in an in-memory histogram, a snapshot of
+// which is returned by Latencies.
+func WithLatencyHistogram() Option {
+	return func(i *Invoker) {
+		i.histogram = newHistogram()
+	}
+}
+
+// Latencies returns a snapshot of the latencies of the attempts the Invoker
+// has made to invoke its lambda function. It's empty unless
+// WithLatencyHistogram is provided.
+func (i *Invoker) Latencies() Latencies {
+	l := Latencies{ARN: i.arn}
+	h := i.histogram
+	if h == nil {
+		return l
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	l.Count = h.count
+	l.Max = h.max
+	l.Buckets = make([]LatencyBucket, len(h.counts))
+	for n, count := range h.counts {
+		bound := InfiniteLatency
+		if n < len(latencyBuckets) {
+			bound = latencyBuckets[n]
+		}
+		l.Buckets[n] = LatencyBucket{
+			UpperBound: bound,
+			Count:      count,
+		}
+	}
+	return l
+}
@@ -0,0 +1,84 @@
+package invoker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithLatencyHistogram(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithLatencyHistogram())
+	wg := sync.WaitGroup{}
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := invoker.Invoke(ctx, nil)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	latencies := invoker.Latencies()
+	assert.Equal(t, arn, latencies.ARN)
+	assert.Equal(t, int64(10), latencies.Count)
+	var counted int64
+	for _, bucket := range latencies.Buckets {
+		counted += bucket.Count
+	}
+	assert.Equal(t, int64(10), counted)
+}
+
+func TestInvokeWithoutLatencyHistogram(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn)
+	_, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), invoker.Latencies().Count)
+	assert.Equal(t, time.Duration(0), invoker.Latencies().Percentile(99))
+}
+
+func TestLatenciesPercentile(t *testing.T) {
+	t.Parallel()
+	h := newHistogram()
+	for n := 0; n < 90; n++ {
+		h.observe(3 * time.Millisecond)
+	}
+	for n := 0; n < 9; n++ {
+		h.observe(200 * time.Millisecond)
+	}
+	h.observe(2 * time.Minute)
+	latencies := (&Invoker{histogram: h}).Latencies()
+	assert.Equal(t, 5*time.Millisecond, latencies.Percentile(50))
+	assert.Equal(t, 5*time.Millisecond, latencies.Percentile(90))
+	assert.Equal(t, 250*time.Millisecond, latencies.Percentile(99))
+	assert.Equal(t, 2*time.Minute, latencies.Percentile(100))
+	assert.Equal(t, 2*time.Minute, latencies.Max)
+	for n := 1; n < len(latencies.Buckets); n++ {
+		assert.Greater(t, latencies.Buckets[n].UpperBound, latencies.Buckets[n-1].UpperBound)
+	}
+	assert.Equal(t, InfiniteLatency, latencies.Buckets[len(latencies.Buckets)-1].UpperBound)
+
+	h = newHistogram()
+	h.observe(time.Millisecond)
+	h.observe(2 * time.Millisecond)
+	latencies = (&Invoker{histogram: h}).Latencies()
+	assert.Equal(t, time.Millisecond, latencies.Percentile(50))
+	assert.Equal(t, 2*time.Millisecond, latencies.Percentile(99))
+}
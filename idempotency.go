@@ -0,0 +1,68 @@
+package invoker
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// IdempotencyStore implementations store the results of invocations keyed on
+// their idempotency key. They must be safe for concurrent use; MemoryCache is
+// an IdempotencyStore.
+type IdempotencyStore interface {
+	// Get returns the value stored for key, if it hasn't expired.
+	Get(key string) (json.RawMessage, bool)
+	// Set stores value for key, expiring it after ttl.
+	Set(key string, value json.RawMessage, ttl time.Duration)
+}
+
+// idempotency dedupes invocations which have the same idempotency key.
+type idempotency struct {
+	keyFn func(json.RawMessage) string
+	store IdempotencyStore
+	ttl   time.Duration
+}
+
+// WithIdempotency returns an option which can be passed when initializing an
+// Invoker. If provided keyFn will be called with the payload sent to the
+// lambda function, after the input mutators have been applied, to derive its
+// idempotency key; if the result of a successful invocation with the same key
+// is in store it's returned without invoking the lambda function, otherwise
+// the function is invoked and a successful result stored for ttl. An empty
+// key disables deduplication for that invocation. Errors are never stored, so
+// failed invocations can be retried. Invocations made concurrently with the
+// same key aren't deduplicated, and keys must be unique across Invokers
+// sharing a store.
+func WithIdempotency(keyFn func(json.RawMessage) string, store IdempotencyStore, ttl time.Duration) Option {
+	return func(i *Invoker) {
+		i.idempotency = &idempotency{
+			keyFn: keyFn,
+			store: store,
+			ttl:   ttl,
+		}
+	}
+}
+
+// key returns the idempotency key of payload, or "" if invocations aren't
+// deduplicated.
+func (d *idempotency) key(payload json.RawMessage) string {
+	if d == nil {
+		return ""
+	}
+	return d.keyFn(payload)
+}
+
+// get returns the stored result of the invocation with key, if any.
+func (d *idempotency) get(key string) (json.RawMessage, bool) {
+	if key == "" {
+		return nil, false
+	}
+	return d.store.Get(key)
+}
+
+// set stores the result of a successful invocation with key.
+func (d *idempotency) set(key string, payload json.RawMessage) {
+	if key == "" {
+		return
+	}
+	d.store.Set(key, payload, d.ttl)
+}
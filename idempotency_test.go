@@ -0,0 +1,79 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderKey derives an idempotency key from the 'orderId' of a payload.
+func orderKey(body json.RawMessage) string {
+	order := struct {
+		OrderID string `json:"orderId"`
+	}{}
+	_ = json.Unmarshal(body, &order)
+	return order.OrderID
+}
+
+func TestInvokeWithIdempotency(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	calls := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		calls++
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"charge":` + strconv.Itoa(calls) + `}`),
+		}, nil
+	})
+	invoker := New(li, arn, WithIdempotency(orderKey, NewMemoryCache(), time.Minute))
+	first, err := invoker.Invoke(ctx, []byte(`{"orderId":"a","amount":1}`))
+	require.NoError(t, err)
+	second, err := invoker.Invoke(ctx, []byte(`{"orderId":"a","amount":2}`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.JSONEq(t, string(first), string(second))
+
+	_, err = invoker.Invoke(ctx, []byte(`{"orderId":"b"}`))
+	require.NoError(t, err)
+	_, err = invoker.Invoke(ctx, []byte(`{}`))
+	require.NoError(t, err)
+	_, err = invoker.Invoke(ctx, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestInvokeWithIdempotencyError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	calls := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		calls++
+		if calls == 1 {
+			return &lambda.InvokeOutput{
+				FunctionError: aws.String("Unhandled"),
+			}, nil
+		}
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{}`),
+		}, nil
+	})
+	invoker := New(li, arn, WithIdempotency(orderKey, NewMemoryCache(), time.Minute))
+	body := []byte(`{"orderId":"a"}`)
+	_, err := invoker.Invoke(ctx, body)
+	require.Error(t, err)
+	for n := 0; n < 2; n++ {
+		_, err = invoker.Invoke(ctx, body)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, calls)
+}
@@ -1,14 +1,20 @@
 package invoker
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	awsreq "github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	router "github.com/edstell/lambda-router"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
 // LambdaInvoker abstracts the logic of invoking a lambda function behind an
@@ -17,19 +23,130 @@ type LambdaInvoker interface {
 	InvokeWithContext(context.Context, *lambda.InvokeInput, ...awsreq.Option) (*lambda.InvokeOutput, error)
 }
 
-// Error wraps an error message with a status code.
-type Error struct {
-	error
-	StatusCode int64
+// RequestOptionsReporter is implemented by LambdaInvokers which report
+// whether they apply the awsreq.Options passed to InvokeWithContext to the
+// request they make. A *lambda.Lambda always applies them; other
+// LambdaInvokers, for example those wrapping a *lambda.Lambda, should
+// implement it so Options which rely on request options can be used.
+type RequestOptionsReporter interface {
+	AppliesRequestOptions() bool
+}
+
+// appliesRequestOptions reports whether li is known to apply the request
+// options it's passed.
+func appliesRequestOptions(li LambdaInvoker) bool {
+	if r, ok := li.(RequestOptionsReporter); ok {
+		return r.AppliesRequestOptions()
+	}
+	_, ok := li.(*lambda.Lambda)
+	return ok
+}
+
+// ignoresRequestOptions reports whether li reports that it doesn't apply the
+// request options it's passed.
+func ignoresRequestOptions(li LambdaInvoker) bool {
+	r, ok := li.(RequestOptionsReporter)
+	return ok && !r.AppliesRequestOptions()
 }
 
 // Invoker is a wrapper around the aws lambda invoker implementation. It
 // provides a convenient layer for middleware, as well as exposing a simpler
 // method to invoke a lambda function with.
 type Invoker struct {
-	li           LambdaInvoker
-	arn          string
-	MutateInput  func(*lambda.InvokeInput) error
+	li             LambdaInvoker
+	arn            string
+	function       FunctionARN
+	crossAccount   *FunctionARN
+	invocationType string
+	procedure      string
+	err            error
+	// optionErr is set by an Option which couldn't be applied.
+	optionErr error
+	// assumeRole is set if the credentials of each request are replaced by
+	// WithAssumeRole.
+	assumeRole     bool
+	opts           []Option
+	fallback       *Invoker
+	retry          retryPolicy
+	idempotent     *bool
+	timeout        time.Duration
+	asyncThreshold time.Duration
+	maxDuration    time.Duration
+	payloadLimits  payloadLimits
+	emptyPayload   json.RawMessage
+	precondition   func(context.Context, json.RawMessage) (bool, json.RawMessage, error)
+	cache          Cache
+	cacheTTL       time.Duration
+	counters       *counters
+	metrics        Metrics
+	middleware     Middleware
+	breaker        *circuitBreaker
+	limiter        *rate.Limiter
+	slots          chan struct{}
+	logger         Logger
+	requestOptions []awsreq.Option
+	tracer         trace.Tracer
+	errorStatus    func(*lambda.InvokeOutput) int64
+	codec          Codec
+	// inspectResponse is called with each router.Response by AsProcedure.
+	inspectResponse func(router.Response)
+	// requestEnvelopeHook is called with each router.Request by AsProcedure.
+	requestEnvelopeHook func(*router.Request) error
+	// decompressResponse gunzips the payload before the response decoder.
+	decompressResponse bool
+	// responseDecoder is applied to the payload before the output mutators.
+	responseDecoder func([]byte) ([]byte, error)
+	// deadlineBuffer is reserved before the deadline of the context passed
+	// to Invoke.
+	deadlineBuffer time.Duration
+	// deadlineFraction is the share of the time remaining before the
+	// deadline of the context passed to Invoke that an invocation may take.
+	deadlineFraction float64
+	// extractTags returns the tags passed to the metrics and logging hooks.
+	extractTags func(context.Context) map[string]string
+	// migrate is applied to the payload once the input has been mutated.
+	migrate func(json.RawMessage) (json.RawMessage, error)
+	// defaultOnError supplies the payload returned in place of an error.
+	defaultOnError func(error) (json.RawMessage, bool)
+	// requireResponse fails successful invocations with an empty payload.
+	requireResponse bool
+	// retryOnError reports whether an error returned by the output mutators
+	// should be retried.
+	retryOnError func(error) bool
+	// canonicalize is applied to payloads before cache and idempotency keys
+	// are derived from them.
+	canonicalize func(json.RawMessage) json.RawMessage
+	// idempotency dedupes invocations with the same idempotency key.
+	idempotency *idempotency
+	// histogram counts the latency of each attempt, if enabled.
+	histogram *histogram
+	// redact is applied to payloads before they're logged.
+	redact func(json.RawMessage) json.RawMessage
+	// payloadLogging logs payloads, truncated to payloadLogLimit bytes.
+	payloadLogging  bool
+	payloadLogLimit int
+	// inspectOutput is called with the raw InvokeOutput of each invocation.
+	inspectOutput func(*lambda.InvokeOutput)
+	// errorPrototype news up the error a router.Response error is unmarshaled
+	// into by AsProcedure.
+	errorPrototype func() error
+	// warn is passed warnings about how the Invoker was configured.
+	warn func(msg string)
+	// warnNotIdempotent ensures the warning that retries are disabled is only
+	// logged once.
+	warnNotIdempotent sync.Once
+	// warnRequestOptions ensures the warning that the request options passed
+	// to an invocation are ignored is only logged once.
+	warnRequestOptions sync.Once
+	inputMutators      []func(context.Context, *lambda.InvokeInput) error
+	outputMutators     []func(*lambda.InvokeOutput) error
+	// MutateInput is called after any input mutators added by Options.
+	//
+	// Deprecated: use AddInputMutator, which composes with other Options.
+	MutateInput func(*lambda.InvokeInput) error
+	// MutateOutput is called before any output mutators added by Options.
+	//
+	// Deprecated: use AddOutputMutator, which composes with other Options.
 	MutateOutput func(*lambda.InvokeOutput) error
 }
 
@@ -37,11 +154,25 @@ type Invoker struct {
 // invokations of a lambda function should be performed.
 type Option func(*Invoker)
 
-// New initializes an Invoker with the options passed.
+// New initializes an Invoker with the options passed. If li is nil, arn is
+// empty or the options are incompatible Invoke will return an error; use
+// NewValidated to receive it immediately.
 func New(li LambdaInvoker, arn string, opts ...Option) *Invoker {
 	invoker := &Invoker{
-		li:  li,
-		arn: arn,
+		li:             li,
+		arn:            arn,
+		invocationType: lambda.InvocationTypeRequestResponse,
+		retry:          retryPolicy{maxAttempts: 1},
+		metrics:        nopMetrics{},
+		middleware:     chain(),
+		codec:          jsonCodec{},
+		errorStatus:    defaultErrorStatus,
+		counters:       &counters{},
+		emptyPayload:   defaultEmptyPayload,
+		payloadLimits: payloadLimits{
+			sync:  DefaultSyncPayloadLimit,
+			async: DefaultAsyncPayloadLimit,
+		},
 		MutateInput: func(i *lambda.InvokeInput) error {
 			return nil
 		},
@@ -52,69 +183,714 @@ func New(li LambdaInvoker, arn string, opts ...Option) *Invoker {
 	for _, opt := range opts {
 		opt(invoker)
 	}
+	if !knownInvocationType(invoker.invocationType) {
+		invoker.warnf("unknown invocation type '%s' for '%s'", invoker.invocationType, arn)
+	}
+	if len(invoker.requestOptions) > 0 && ignoresRequestOptions(li) {
+		invoker.warnf("request options are ignored by the lambda invoker for '%s'", arn)
+	}
+	invoker.opts = opts
+	invoker.err = invoker.validate()
 	return invoker
 }
 
+// Clone returns a new Invoker configured in the same way as i, with opts
+// applied after the options i was initialized with; i isn't modified. The
+// clone is built by reapplying every option, so it has its own mutator chains
+// along with its own circuit breaker and rate limiter state.
+func (i *Invoker) Clone(opts ...Option) *Invoker {
+	all := make([]Option, 0, len(i.opts)+len(opts))
+	all = append(all, i.opts...)
+	all = append(all, opts...)
+	clone := New(i.li, i.arn, all...)
+	clone.function = i.function
+	clone.MutateInput = i.MutateInput
+	clone.MutateOutput = i.MutateOutput
+	return clone
+}
+
+// ARN returns the name or ARN of the lambda function the Invoker was
+// initialized with; it isn't rewritten by WithCrossAccount or input mutators.
+func (i *Invoker) ARN() string {
+	return i.arn
+}
+
+// InvocationType returns the InvocationType the lambda function is invoked
+// with by default; input mutators and CallInvocationType can override it for
+// an individual invocation.
+func (i *Invoker) InvocationType() string {
+	return i.invocationType
+}
+
+// validate checks the options the Invoker was configured with are compatible
+// with each other.
+func (i *Invoker) validate() error {
+	if i.optionErr != nil {
+		return i.optionErr
+	}
+	if i.li == nil {
+		return errors.New("lambda invoker can't be nil")
+	}
+	if i.arn == "" {
+		return errors.New("arn can't be empty")
+	}
+	if i.assumeRole && !appliesRequestOptions(i.li) {
+		return errors.New("lambda invoker doesn't apply request options, so can't assume a role")
+	}
+	if err := i.validateCrossAccount(); err != nil {
+		return err
+	}
+	return i.validateInvocationType(i.invocationType)
+}
+
+// validateInvocationType checks the Invoker can invoke the lambda function
+// with the invocationType passed.
+func (i *Invoker) validateInvocationType(invocationType string) error {
+	if invocationType == lambda.InvocationTypeEvent && i.procedure != "" {
+		return fmt.Errorf("procedure '%s' can't be invoked as an event", i.procedure)
+	}
+	return nil
+}
+
 // Invoke _invokes_ the lambda function passing body as the InvokeInput.Payload
-// and returning the InvokeOutput.Payload as the result. If InvokeOutput
+// and returning the InvokeOutput.Payload as the result. A nil or empty body is
+// sent as 'null', unless WithEmptyPayload is provided. If InvokeOutput
 // contains a FunctionError an Error is returned, wrapping the status code.
+// Failures to invoke the function are wrapped in an InvocationError
+// describing the kind of failure; errors.As converts function errors to one
+// too.
 // By default lambda functions are invoked as a 'RequestResponse', but
-// input mutators can be passed to change the InvocationType.
+// input mutators can be passed to change the InvocationType. When invoked as
+// an 'Event' or 'DryRun' no output is returned once the invocation has been
+// accepted.
 func (i *Invoker) Invoke(ctx context.Context, body json.RawMessage, opts ...awsreq.Option) (json.RawMessage, error) {
+	payload, _, err := i.InvokeWithMeta(ctx, body, opts...)
+	return payload, err
+}
+
+// call invokes the lambda function, through any Middleware, as configured by
+// call; returning the result along with metadata describing the invocation.
+func (i *Invoker) call(ctx context.Context, body json.RawMessage, call *callConfig) (json.RawMessage, Meta, error) {
+	meta := &Meta{}
+	if i.err != nil {
+		i.counters.record(i.err)
+		return nil, *meta, i.err
+	}
+	if len(call.requestOptions) > 0 && ignoresRequestOptions(i.li) {
+		i.warnRequestOptions.Do(func() {
+			i.warnf("request options passed to invoke '%s' are ignored by the lambda invoker", i.arn)
+		})
+	}
+	invoke := func(ctx context.Context, body json.RawMessage) (json.RawMessage, error) {
+		return i.invoke(ctx, body, call)
+	}
+	ctx = context.WithValue(i.withInvocation(ctx), metaKey{}, meta)
+	payload, err := i.middleware(invoke)(ctx, body)
+	i.counters.record(err)
+	if err != nil && i.defaultOnError != nil && !call.skipDefaultOnError && !errors.Is(err, ErrInvokedAsync) {
+		var fallback json.RawMessage
+		var ok bool
+		if perr := guard(func() error {
+			fallback, ok = i.defaultOnError(err)
+			return nil
+		}); perr != nil {
+			return nil, *meta, perr
+		}
+		if ok {
+			return fallback, *meta, nil
+		}
+	}
+	return payload, *meta, err
+}
+
+// invoke performs the invocation of the lambda function, it's wrapped by any
+// Middleware the Invoker was configured with. The invocation passes through
+// each stage in turn: the input is built and checked, the result of an
+// earlier invocation is looked up, the function is invoked through the
+// resilience policies, then the result is completed.
+func (i *Invoker) invoke(ctx context.Context, body json.RawMessage, call *callConfig) (json.RawMessage, error) {
+	inv := &invokeState{
+		parent: ctx,
+		body:   body,
+		call:   call,
+	}
+	ctx, cancel, err := i.reserveDeadline(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	if timeout := i.invocationTimeout(call); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if inv.input, err = i.buildInput(ctx, body, call); err != nil {
+		return nil, err
+	}
+	skip, result, err := i.checkPrecondition(ctx, inv.input)
+	if err != nil {
+		return nil, err
+	}
+	if skip {
+		return result, nil
+	}
+	if err := i.preCheck(inv); err != nil {
+		return nil, err
+	}
+	if payload, ok, err := i.lookup(inv); err != nil || ok {
+		return payload, err
+	}
+	if err := i.invokeResilient(ctx, inv); err != nil {
+		return nil, err
+	}
+	if inv.useFallback {
+		return i.invokeFallback(inv.parent, body, call)
+	}
+	return i.complete(inv)
+}
+
+// invokeState is the state of a single call to invoke, as it passes through
+// each of its stages.
+type invokeState struct {
+	// parent is the context passed to invoke, before the deadline buffer and
+	// timeouts are applied; the fallback is invoked with it.
+	parent context.Context
+	body   json.RawMessage
+	call   *callConfig
+	input  *lambda.InvokeInput
+	// async is set if the invocation is made as an event by
+	// WithAsyncFallback.
+	async          bool
+	idempotencyKey string
+	cacheKey       string
+	cacheable      bool
+	// useFallback is set if the fallback Invoker should be invoked instead.
+	useFallback bool
+	output      *lambda.InvokeOutput
+	// functionErr is the error reported by the lambda function, if any.
+	functionErr error
+}
+
+// buildInput builds the input the lambda function is invoked with, applying
+// the input mutators, the overrides of call and the payload migration to
+// body.
+func (i *Invoker) buildInput(ctx context.Context, body json.RawMessage, call *callConfig) (*lambda.InvokeInput, error) {
 	input := &lambda.InvokeInput{
 		FunctionName:   aws.String(i.arn),
-		InvocationType: aws.String("RequestResponse"),
-		Payload:        body,
+		InvocationType: aws.String(i.invocationType),
+		Payload:        i.normalizePayload(body),
 	}
-	if err := i.MutateInput(input); err != nil {
+	if err := i.mutateInput(ctx, input); err != nil {
 		return nil, err
 	}
-	output, err := i.li.InvokeWithContext(ctx, input, opts...)
-	if err != nil {
+	if err := call.mutateInput(input); err != nil {
 		return nil, err
 	}
-	if err := i.MutateOutput(output); err != nil {
+	if err := i.migratePayload(input); err != nil {
 		return nil, err
 	}
-	if message := output.FunctionError; message != nil {
-		statusCode := int64(-1)
-		if output.StatusCode != nil {
-			statusCode = *output.StatusCode
+	return input, nil
+}
+
+// checkPrecondition calls the precondition, if there is one, with the payload
+// of input; reporting whether the invocation should be skipped, and the result
+// to return in its place.
+func (i *Invoker) checkPrecondition(ctx context.Context, input *lambda.InvokeInput) (bool, json.RawMessage, error) {
+	if i.precondition == nil {
+		return false, nil, nil
+	}
+	var skip bool
+	var result json.RawMessage
+	if err := guard(func() (err error) {
+		skip, result, err = i.precondition(ctx, input.Payload)
+		return err
+	}); err != nil {
+		return false, nil, err
+	}
+	return skip, result, nil
+}
+
+// preCheck decides whether the invocation falls back to being made as an
+// event, then checks its input can be sent.
+func (i *Invoker) preCheck(inv *invokeState) error {
+	inv.async = i.fallbackToAsync(inv.parent, inv.input)
+	if !inv.async {
+		if err := i.validateInvocationType(aws.StringValue(inv.input.InvocationType)); err != nil {
+			return err
+		}
+	}
+	return i.checkPayloadSize(inv.input)
+}
+
+// lookup derives the idempotency and cache keys of the invocation, returning
+// the stored result of an earlier invocation with either key, if there is
+// one.
+func (i *Invoker) lookup(inv *invokeState) (json.RawMessage, bool, error) {
+	if err := guard(func() error {
+		inv.idempotencyKey = i.idempotency.key(i.canonicalKeyPayload(inv.input.Payload))
+		inv.cacheKey, inv.cacheable = i.cacheKey(inv.input)
+		return nil
+	}); err != nil {
+		return nil, false, err
+	}
+	if payload, ok := i.idempotency.get(inv.idempotencyKey); ok {
+		return payload, true, nil
+	}
+	if inv.cacheable {
+		if payload, ok := i.cache.Get(inv.cacheKey); ok {
+			return payload, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// invokeResilient invokes the lambda function through the circuit breaker
+// and retry policy, then applies the output mutators to the output of a
+// 'RequestResponse' invocation. If the fallback should be invoked instead
+// useFallback is set rather than an error being returned.
+func (i *Invoker) invokeResilient(ctx context.Context, inv *invokeState) error {
+	// Errors returned by the output mutators may be retried, as configured by
+	// RetryOnError, so each round invokes the function through the retry
+	// policy then mutates the output; attempts are shared between rounds.
+	policy := i.retryPolicy()
+	attempts, wait := 0, time.Duration(0)
+	// hookErr is a panic raised by a hook observing the invocation, it's
+	// returned once the outcome of the invocation has been recorded.
+	var hookErr error
+	for {
+		if !i.breaker.allow() {
+			if i.fallback != nil {
+				inv.useFallback = true
+				return nil
+			}
+			return ErrCircuitOpen
+		}
+		round := policy
+		round.maxAttempts -= attempts
+		output, err := round.do(ctx, func(attempt int, delay time.Duration) (*lambda.InvokeOutput, error) {
+			attempts++
+			if attempt == 1 {
+				delay = wait
+			}
+			return i.attempt(ctx, attempts, delay, inv.input, i.breaker, &hookErr, inv.call.requestOptions...)
+		})
+		if output != nil && i.inspectOutput != nil {
+			if perr := guard(func() error {
+				i.inspectOutput(output)
+				return nil
+			}); perr != nil && hookErr == nil {
+				hookErr = perr
+			}
+		}
+		if i.fallback != nil && inv.parent.Err() == nil && shouldFallback(output, err) {
+			inv.useFallback = true
+			return nil
+		}
+		recordMeta(ctx, output)
+		if err != nil {
+			return newInvocationError(err)
+		}
+		if hookErr != nil {
+			return hookErr
+		}
+		inv.output = output
+		if inv.async {
+			return nil
+		}
+		switch aws.StringValue(inv.input.InvocationType) {
+		case lambda.InvocationTypeEvent, lambda.InvocationTypeDryRun:
+			return nil
+		}
+		inv.functionErr = nil
+		if output.FunctionError != nil {
+			inv.functionErr = i.newFunctionError(output)
+		}
+		err = i.mutateOutput(ctx, output)
+		if err == nil {
+			return nil
+		}
+		if !i.retryError(policy, attempts, err) {
+			return err
+		}
+		wait = policy.backoff(attempts)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return newInvocationError(err)
 		}
-		return nil, &Error{errors.New(*message), statusCode}
 	}
-	return output.Payload, nil
+}
+
+// complete returns the result of the invocation, storing a successful result
+// against its idempotency and cache keys.
+func (i *Invoker) complete(inv *invokeState) (json.RawMessage, error) {
+	if inv.async {
+		return nil, ErrInvokedAsync
+	}
+	switch aws.StringValue(inv.input.InvocationType) {
+	case lambda.InvocationTypeEvent:
+		i.idempotency.set(inv.idempotencyKey, nil)
+		return nil, nil
+	case lambda.InvocationTypeDryRun:
+		return nil, nil
+	}
+	if inv.functionErr != nil {
+		return nil, inv.functionErr
+	}
+	if i.requireResponse && len(inv.output.Payload) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	if inv.cacheable {
+		i.cache.Set(inv.cacheKey, inv.output.Payload, i.cacheTTL)
+	}
+	i.idempotency.set(inv.idempotencyKey, inv.output.Payload)
+	return inv.output.Payload, nil
+}
+
+// invocationTimeout returns how long an invocation configured by call may
+// take, as limited by WithTimeout, CallTimeout and WithMaxDuration; or zero
+// if it isn't limited.
+func (i *Invoker) invocationTimeout(call *callConfig) time.Duration {
+	timeout := i.timeout
+	if call.timeout > 0 {
+		timeout = call.timeout
+	}
+	if i.maxDuration > 0 && (timeout <= 0 || i.maxDuration < timeout) {
+		timeout = i.maxDuration
+	}
+	return timeout
+}
+
+// ready waits until an attempt to invoke the lambda function may be made, as
+// limited by the rate limiter and bulkhead, returning a func which must be
+// called once it completes.
+func (i *Invoker) ready(ctx context.Context) (func(), error) {
+	// Don't start an attempt once the caller has given up; the context may
+	// have been cancelled while mutating the input, or between retries.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := i.wait(ctx); err != nil {
+		return nil, err
+	}
+	return i.acquire(ctx)
+}
+
+// attempt makes a single call to the LambdaInvoker, recording its outcome
+// with breaker, if it isn't nil, and the Invoker's hooks; delay is how long
+// was waited before making it. If recording the outcome panics, and hookErr
+// isn't already set, it's set to an ErrMutatorPanic.
+func (i *Invoker) attempt(ctx context.Context, attempt int, delay time.Duration, input *lambda.InvokeInput, breaker *circuitBreaker, hookErr *error, opts ...awsreq.Option) (*lambda.InvokeOutput, error) {
+	release, err := i.ready(ctx)
+	if err != nil {
+		breaker.abandon()
+		return nil, err
+	}
+	defer release()
+	ctx, endSpan := i.startSpan(ctx, input)
+	var requestID string
+	opts = append(i.withRequestOptions(opts), captureRequestID(&requestID))
+	start := time.Now()
+	output, err := i.li.InvokeWithContext(ctx, input, opts...)
+	latency := time.Since(start)
+	endSpan(output, err)
+	breaker.record(output, err)
+	if requestID == "" {
+		requestID = errorRequestID(err)
+	}
+	recordRequestID(ctx, requestID)
+	if perr := guard(func() error {
+		tags := i.tags(ctx)
+		i.observe(latency, tags, output, err)
+		i.log(attempt, delay, latency, requestID, tags, input, output, err)
+		return nil
+	}); perr != nil && *hookErr == nil {
+		*hookErr = perr
+	}
+	return output, err
 }
 
 // AsProcedure returns an option which can be passed when initializing an
 // Invoker. If provided it will configure invocation to be performed as a call
-// to the named procedure.
+// to the named procedure. Errors returned by the procedure are passed to
+// unmarshalError, unless WithErrorPrototype is also provided; if it's nil they
+// are returned as a *ProcedureError carrying the raw error. If the response
+// envelope has a 'statusCode' field it's exposed as the ProcedureStatusCode
+// of Meta, and errors are wrapped in an Error with it as their StatusCode. If
+// it has a body along with an error, the error is wrapped in a
+// PartialResultError carrying the body.
 func AsProcedure(procedure string, unmarshalError func(json.RawMessage) error) Option {
+	return asProcedure(procedure, func(body json.RawMessage) router.Request {
+		return router.Request{
+			Procedure: procedure,
+			Body:      body,
+		}
+	}, unmarshalError)
+}
+
+// AsProcedureFunc returns an option which can be passed when initializing an
+// Invoker. It configures invocation in the same way as AsProcedure, but the
+// router.Request sent is constructed by build, so fields other than the
+// Procedure and Body can be populated. Errors returned by the procedure are
+// unmarshaled as configured by WithErrorPrototype, or otherwise returned with
+// the raw error as their message.
+func AsProcedureFunc(procedure string, build func(body json.RawMessage) router.Request) Option {
+	return asProcedure(procedure, build, nil)
+}
+
+// asProcedure configures invocation to be performed as a call to the named
+// procedure, with the router.Request constructed by build.
+func asProcedure(procedure string, build func(json.RawMessage) router.Request, unmarshalError func(json.RawMessage) error) Option {
+	if unmarshalError == nil {
+		unmarshalError = func(e json.RawMessage) error {
+			return &ProcedureError{Raw: e}
+		}
+	}
 	return func(i *Invoker) {
-		i.MutateInput = func(input *lambda.InvokeInput) error {
-			bytes, err := json.Marshal(router.Request{
-				Procedure: procedure,
-				Body:      input.Payload,
-			})
+		i.procedure = procedure
+		i.inputMutators = append(i.inputMutators, func(ctx context.Context, input *lambda.InvokeInput) error {
+			req := build(input.Payload)
+			if procedure, ok := i.procedureOverride(ctx); ok {
+				req.Procedure = procedure
+			}
+			bytes, err := buildProcedureRequest(i.codec, req, i.requestEnvelopeHook)
 			if err != nil {
 				return err
 			}
 			input.Payload = bytes
 			return nil
-		}
-		i.MutateOutput = func(output *lambda.InvokeOutput) error {
-			if output.Payload == nil {
+		})
+		i.outputMutators = append(i.outputMutators, func(output *lambda.InvokeOutput) error {
+			if output.Payload == nil || output.FunctionError != nil {
 				return nil
 			}
 			rsp := &router.Response{}
-			if err := json.Unmarshal(output.Payload, rsp); err != nil {
+			if err := i.codec.Unmarshal(output.Payload, rsp); err != nil {
 				return err
 			}
+			if i.inspectResponse != nil {
+				i.inspectResponse(*rsp)
+			}
 			if rsp.Error == nil {
 				output.Payload = rsp.Body
 				return nil
 			}
-			return unmarshalError(rsp.Error)
+			var err error
+			if i.errorPrototype != nil {
+				err = i.unmarshalErrorPrototype(rsp.Error)
+			} else {
+				err = unmarshalError(rsp.Error)
+			}
+			if len(rsp.Body) > 0 && !bytes.Equal(rsp.Body, defaultEmptyPayload) {
+				err = &PartialResultError{
+					error: err,
+					Body:  rsp.Body,
+				}
+			}
+			if status := procedureStatus(output.Payload); status != 0 {
+				return &Error{
+					error:      err,
+					StatusCode: status,
+					Payload:    rsp.Error,
+				}
+			}
+			return err
+		})
+	}
+}
+
+// BuildProcedureRequest returns the router.Request envelope AsProcedure sends
+// to call the named procedure with body, marshaled as json. It's useful for
+// testing, or invoking a procedure over a transport other than an Invoker.
+func BuildProcedureRequest(procedure string, body json.RawMessage) (json.RawMessage, error) {
+	return buildProcedureRequest(jsonCodec{}, router.Request{
+		Procedure: procedure,
+		Body:      body,
+	}, nil)
+}
+
+// buildProcedureRequest passes req to hook, if there is one, and then
+// marshals it with codec.
+func buildProcedureRequest(codec Codec, req router.Request, hook func(*router.Request) error) (json.RawMessage, error) {
+	if hook != nil {
+		if err := hook(&req); err != nil {
+			return nil, err
 		}
 	}
+	return codec.Marshal(req)
+}
+
+// WithResponseInspector returns an option which can be passed when
+// initializing an Invoker. If provided with AsProcedure, inspect will be
+// called with each router.Response received, before the body or error is
+// returned.
+func WithResponseInspector(inspect func(router.Response)) Option {
+	return func(i *Invoker) {
+		i.inspectResponse = inspect
+	}
+}
+
+// WithRequestEnvelopeHook returns an option which can be passed when
+// initializing an Invoker. If provided with AsProcedure, hook will be called
+// with each router.Request after it's built, and may modify it before it's
+// marshaled and sent. If hook returns an error the invocation is aborted and
+// the error returned.
+func WithRequestEnvelopeHook(hook func(*router.Request) error) Option {
+	return func(i *Invoker) {
+		i.requestEnvelopeHook = hook
+	}
+}
+
+// WithOutputInspector returns an option which can be passed when initializing
+// an Invoker. If provided, inspect will be called with the InvokeOutput of
+// each invocation before it's processed, including when the function returned
+// an error; it mustn't modify the output. If the invocation was retried it's
+// called with the output of the final attempt.
+func WithOutputInspector(inspect func(*lambda.InvokeOutput)) Option {
+	return func(i *Invoker) {
+		i.inspectOutput = inspect
+	}
+}
+
+// WithErrorPrototype returns an option which can be passed when initializing
+// an Invoker. If provided with AsProcedure, errors returned by the procedure
+// will be unmarshaled into a fresh error returned by prototype, rather than
+// by the unmarshalError passed to AsProcedure. The prototype must return a
+// pointer, which is returned as is; so callers can use errors.As to retrieve
+// it:
+//
+//	inv := invoker.New(li, arn,
+//		invoker.AsProcedure("Do", nil),
+//		invoker.WithErrorPrototype(func() error { return &MyError{} }),
+//	)
+//	_, err := inv.Invoke(ctx, body)
+//	var myErr *MyError
+//	if errors.As(err, &myErr) {
+//		...
+//	}
+func WithErrorPrototype(prototype func() error) Option {
+	return func(i *Invoker) {
+		i.errorPrototype = prototype
+	}
+}
+
+// unmarshalErrorPrototype unmarshals raw into a new error created by the
+// Invoker's errorPrototype.
+func (i *Invoker) unmarshalErrorPrototype(raw json.RawMessage) error {
+	err := i.errorPrototype()
+	if uerr := i.codec.Unmarshal(raw, err); uerr != nil {
+		return fmt.Errorf("unmarshaling procedure error: %w", uerr)
+	}
+	return err
+}
+
+// AsEvent returns an option which can be passed when initializing an Invoker.
+// If provided the lambda function will be invoked asynchronously as an
+// 'Event'; Invoke will return as soon as the invocation has been accepted,
+// without a result. It can't be combined with AsProcedure, since no response
+// will be returned to unmarshal.
+func AsEvent() Option {
+	return func(i *Invoker) {
+		i.invocationType = lambda.InvocationTypeEvent
+	}
+}
+
+// DryRun returns an option which can be passed when initializing an Invoker.
+// If provided the lambda function won't be executed, instead Invoke will
+// verify the caller is permitted to invoke it, returning an error if not.
+func DryRun() Option {
+	return func(i *Invoker) {
+		i.invocationType = lambda.InvocationTypeDryRun
+	}
+}
+
+// WithInvocationType returns an option which can be passed when initializing
+// an Invoker. If provided the lambda function will be invoked with the
+// InvocationType t; AsEvent and DryRun should be preferred where they apply.
+// Values other than those known to lambda are passed to the warning handler,
+// see WithWarningHandler, but still used in case lambda has added new
+// invocation types.
+func WithInvocationType(t string) Option {
+	return func(i *Invoker) {
+		i.invocationType = t
+	}
+}
+
+// knownInvocationType reports whether t is one of the invocation types
+// supported by lambda.
+func knownInvocationType(t string) bool {
+	switch t {
+	case lambda.InvocationTypeRequestResponse, lambda.InvocationTypeEvent, lambda.InvocationTypeDryRun:
+		return true
+	}
+	return false
+}
+
+// WithQualifier returns an option which can be passed when initializing an
+// Invoker. If provided the version or alias named by qualifier will be
+// invoked, rather than $LATEST.
+func WithQualifier(qualifier string) Option {
+	return AddInputMutator(func(input *lambda.InvokeInput) error {
+		input.Qualifier = aws.String(qualifier)
+		return nil
+	})
+}
+
+// WithTimeout returns an option which can be passed when initializing an
+// Invoker. If provided each call to Invoke will fail if it hasn't completed
+// within d, including any retries. If the context passed to Invoke has an
+// earlier deadline that is respected instead.
+func WithTimeout(d time.Duration) Option {
+	return func(i *Invoker) {
+		i.timeout = d
+	}
+}
+
+// WithPrecondition returns an option which can be passed when initializing an
+// Invoker. If provided, precondition will be called with the payload of each
+// invocation once the input mutators have been applied. If it returns true
+// for skip, Invoke returns result without invoking the lambda function; if it
+// returns an error, Invoke returns it.
+func WithPrecondition(precondition func(ctx context.Context, body json.RawMessage) (skip bool, result json.RawMessage, err error)) Option {
+	return func(i *Invoker) {
+		i.precondition = precondition
+	}
+}
+
+// WithMaxDuration returns an option which can be passed when initializing an
+// Invoker. If provided each call to Invoke will fail if it hasn't completed
+// within d, regardless of the deadline of the context passed to Invoke or any
+// timeout set by WithTimeout or CallTimeout; whichever is shorter applies.
+func WithMaxDuration(d time.Duration) Option {
+	return func(i *Invoker) {
+		i.maxDuration = d
+	}
+}
+
+// WithRequestOptions returns an option which can be passed when initializing
+// an Invoker. If provided opts will be passed to the LambdaInvoker with every
+// invocation, allowing the request made by the aws sdk to be customized. Any
+// options passed to Invoke, or by CallRequestOptions, are applied after opts.
+// LambdaInvokers which report that they don't apply request options, such as
+// the invokerv2 Adapter, ignore them; a warning is passed to the handler set
+// by WithWarningHandler if they're used with one.
+func WithRequestOptions(opts ...awsreq.Option) Option {
+	return func(i *Invoker) {
+		i.requestOptions = append(i.requestOptions, opts...)
+	}
+}
+
+// WithUserAgent returns an option which can be passed when initializing an
+// Invoker. If provided suffix will be appended to the User-Agent of the
+// requests made to invoke the lambda function, after the sdk's default.
+func WithUserAgent(suffix string) Option {
+	return WithRequestOptions(awsreq.WithAppendUserAgent(suffix))
+}
+
+// withRequestOptions returns the request options of the Invoker followed by
+// opts.
+func (i *Invoker) withRequestOptions(opts []awsreq.Option) []awsreq.Option {
+	all := make([]awsreq.Option, 0, len(i.requestOptions)+len(opts)+1)
+	all = append(all, i.requestOptions...)
+	return append(all, opts...)
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	awsreq "github.com/aws/aws-sdk-go/aws/request"
@@ -21,6 +22,10 @@ func (f LambdaInvokerFunc) InvokeWithContext(ctx context.Context, i *lambda.Invo
 	return f(ctx, i, opts...)
 }
 
+func (f LambdaInvokerFunc) AppliesRequestOptions() bool {
+	return true
+}
+
 func TestInvoke(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -145,3 +150,504 @@ func TestInvokeAsProcedureWithError(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, "error", e.Complex)
 }
+
+func TestInvokeAsEvent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	body := json.RawMessage(`{"key":"value"}`)
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.Equal(t, lambda.InvocationTypeEvent, *i.InvocationType)
+		assert.Equal(t, string(body), string(i.Payload))
+		return &lambda.InvokeOutput{
+			StatusCode: aws.Int64(http.StatusAccepted),
+		}, nil
+	})
+	invoker := New(li, arn, AsEvent())
+	result, err := invoker.Invoke(ctx, body)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestInvokeAsEventWithProcedure(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda should not be invoked")
+		return nil, nil
+	})
+	invoker := New(li, arn, AsEvent(), AsProcedure("Do", func(e json.RawMessage) error {
+		return errors.New(string(e))
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+}
+
+func TestInvokeWithQualifier(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	procedure := "Do"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.Equal(t, "prod", *i.Qualifier)
+		req := &router.Request{}
+		require.NoError(t, json.Unmarshal(i.Payload, req))
+		assert.Equal(t, procedure, req.Procedure)
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, AsProcedure(procedure, func(e json.RawMessage) error {
+		return errors.New(string(e))
+	}), WithQualifier("prod"))
+	_, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+}
+
+func TestInvokeWithTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(ctx context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return &lambda.InvokeOutput{}, nil
+		}
+	})
+	invoker := New(li, arn, WithTimeout(10*time.Millisecond))
+	_, err := invoker.Invoke(ctx, nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestInvokeWithMaxDuration(t *testing.T) {
+	t.Parallel()
+	arn := "test-arn"
+	var remaining []time.Duration
+	li := LambdaInvokerFunc(func(ctx context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		remaining = append(remaining, time.Until(deadline))
+		return &lambda.InvokeOutput{}, nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+	_, err := New(li, arn, WithMaxDuration(time.Minute)).Invoke(ctx, nil)
+	require.NoError(t, err)
+	_, err = New(li, arn, WithMaxDuration(time.Minute), WithTimeout(time.Second)).Invoke(ctx, nil)
+	require.NoError(t, err)
+	_, err = New(li, arn, WithMaxDuration(time.Second)).InvokeWith(ctx, nil, CallTimeout(time.Minute))
+	require.NoError(t, err)
+
+	require.Len(t, remaining, 3)
+	assert.InDelta(t, time.Minute, remaining[0], float64(time.Second))
+	assert.LessOrEqual(t, remaining[1], time.Second)
+	assert.LessOrEqual(t, remaining[2], time.Second)
+}
+
+func TestInvokeDryRun(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.Equal(t, lambda.InvocationTypeDryRun, *i.InvocationType)
+		return &lambda.InvokeOutput{
+			StatusCode: aws.Int64(http.StatusNoContent),
+		}, nil
+	})
+	invoker := New(li, arn, DryRun())
+	result, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestInvokeDryRunDenied(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return nil, assert.AnError
+	})
+	invoker := New(li, arn, DryRun())
+	_, err := invoker.Invoke(ctx, nil)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestInvokeAsProcedureWithResponseInspector(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: json.RawMessage(`{"error":"failed"}`),
+		}, nil
+	})
+	var inspected router.Response
+	invoker := New(li, arn, AsProcedure("Do", func(e json.RawMessage) error {
+		return errors.New(string(e))
+	}), WithResponseInspector(func(rsp router.Response) {
+		inspected = rsp
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.Equal(t, `"failed"`, string(inspected.Error))
+}
+
+type prototypeError struct {
+	Message string `json:"message"`
+}
+
+func (e *prototypeError) Error() string {
+	return e.Message
+}
+
+func TestInvokeAsProcedureWithErrorPrototype(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	procedure := "Do"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"error":{"message":"failed"}}`),
+		}, nil
+	})
+	invoker := New(li, arn, AsProcedure(procedure, nil), WithErrorPrototype(func() error {
+		return &prototypeError{}
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	var e *prototypeError
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "failed", e.Message)
+
+	_, err = invoker.Invoke(ctx, nil)
+	var other *prototypeError
+	require.True(t, errors.As(err, &other))
+	assert.NotSame(t, e, other)
+}
+
+func TestInvokeAsProcedureWithErrorPrototypeUnmarshalFailure(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	procedure := "Do"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"error":"failed"}`),
+		}, nil
+	})
+	invoker := New(li, arn, AsProcedure(procedure, nil), WithErrorPrototype(func() error {
+		return &prototypeError{}
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	var e *prototypeError
+	assert.False(t, errors.As(err, &e))
+	var jsonErr *json.UnmarshalTypeError
+	assert.True(t, errors.As(err, &jsonErr))
+}
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var inputs []*lambda.InvokeInput
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		inputs = append(inputs, input)
+		return &lambda.InvokeOutput{}, nil
+	})
+	base := New(li, arn, WithQualifier("prod"))
+	clone := base.Clone(WithQualifier("canary"), AddInputMutator(func(input *lambda.InvokeInput) error {
+		input.LogType = aws.String(lambda.LogTypeTail)
+		return nil
+	}))
+	_, err := clone.Invoke(ctx, nil)
+	require.NoError(t, err)
+	_, err = base.Invoke(ctx, nil)
+	require.NoError(t, err)
+
+	require.Len(t, inputs, 2)
+	assert.Equal(t, "canary", *inputs[0].Qualifier)
+	assert.Equal(t, lambda.LogTypeTail, *inputs[0].LogType)
+	assert.Equal(t, "prod", *inputs[1].Qualifier)
+	assert.Nil(t, inputs[1].LogType)
+}
+
+func TestCloneInvalid(t *testing.T) {
+	t.Parallel()
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	base := New(li, "test-arn", AsProcedure("Do", nil))
+	_, err := base.Clone(AsEvent()).Invoke(context.Background(), nil)
+	assert.Error(t, err)
+}
+
+func TestInvokeWithInvocationType(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var invocationTypes []string
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		invocationTypes = append(invocationTypes, *input.InvocationType)
+		return &lambda.InvokeOutput{}, nil
+	})
+	var warnings []string
+	warn := WithWarningHandler(func(msg string) {
+		warnings = append(warnings, msg)
+	})
+	_, err := New(li, arn, WithInvocationType(lambda.InvocationTypeEvent), warn).Invoke(ctx, nil)
+	require.NoError(t, err)
+	_, err = New(li, arn, WithInvocationType("Future"), warn).Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"unknown invocation type 'Future' for 'test-arn'"}, warnings)
+	_, err = New(li, arn).Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{lambda.InvocationTypeEvent, "Future", lambda.InvocationTypeRequestResponse}, invocationTypes)
+	assert.True(t, knownInvocationType(lambda.InvocationTypeDryRun))
+	assert.False(t, knownInvocationType("Future"))
+}
+
+func TestInvokeWithOutputInspector(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError:   aws.String("Unhandled"),
+			ExecutedVersion: aws.String("3"),
+			Payload:         []byte(`{"errorMessage":"failed"}`),
+		}, nil
+	})
+	var inspected *lambda.InvokeOutput
+	invoker := New(li, arn, AsProcedure("Do", nil), WithOutputInspector(func(o *lambda.InvokeOutput) {
+		inspected = o
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	require.NotNil(t, inspected)
+	assert.Equal(t, "3", *inspected.ExecutedVersion)
+	assert.Equal(t, `{"errorMessage":"failed"}`, string(inspected.Payload))
+}
+
+func TestInvokeWithPrecondition(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	calls := 0
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		calls++
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"body":"invoked"}`),
+		}, nil
+	})
+	var seen []string
+	invoker := New(li, arn, AsProcedure("Do", nil), WithPrecondition(func(_ context.Context, body json.RawMessage) (bool, json.RawMessage, error) {
+		req := router.Request{}
+		require.NoError(t, json.Unmarshal(body, &req))
+		seen = append(seen, req.Procedure)
+		switch string(req.Body) {
+		case `"skip"`:
+			return true, json.RawMessage(`"skipped"`), nil
+		case `"fail"`:
+			return false, nil, assert.AnError
+		}
+		return false, nil, nil
+	}))
+
+	result, err := invoker.Invoke(ctx, json.RawMessage(`"skip"`))
+	require.NoError(t, err)
+	assert.Equal(t, `"skipped"`, string(result))
+	_, err = invoker.Invoke(ctx, json.RawMessage(`"fail"`))
+	assert.Equal(t, assert.AnError, err)
+	result, err = invoker.Invoke(ctx, json.RawMessage(`"invoke"`))
+	require.NoError(t, err)
+	assert.Equal(t, `"invoked"`, string(result))
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"Do", "Do", "Do"}, seen)
+}
+
+func TestInvokeAsProcedureFunc(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var req router.Request
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		require.NoError(t, json.Unmarshal(i.Payload, &req))
+		if string(req.Body) == `"fail"` {
+			return &lambda.InvokeOutput{
+				Payload: []byte(`{"error":{"message":"failed"}}`),
+			}, nil
+		}
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"body":"result"}`),
+		}, nil
+	})
+	invoker := New(li, arn, AsProcedureFunc("Do", func(body json.RawMessage) router.Request {
+		return router.Request{
+			Procedure: "Do",
+			Body:      json.RawMessage(`{"wrapped":` + string(body) + `}`),
+		}
+	}))
+	result, err := invoker.Invoke(ctx, json.RawMessage(`"content"`))
+	require.NoError(t, err)
+	assert.Equal(t, `"result"`, string(result))
+	assert.Equal(t, "Do", req.Procedure)
+	assert.JSONEq(t, `{"wrapped":"content"}`, string(req.Body))
+
+	_, err = New(li, arn, AsProcedureFunc("Do", func(body json.RawMessage) router.Request {
+		return router.Request{Procedure: "Do", Body: body}
+	})).Invoke(ctx, json.RawMessage(`"fail"`))
+	require.Error(t, err)
+	assert.JSONEq(t, `{"message":"failed"}`, err.Error())
+
+	_, err = New(li, arn, AsEvent(), AsProcedureFunc("Do", nil)).Invoke(ctx, nil)
+	assert.Error(t, err)
+}
+
+func TestInvokeCancelled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(ctx context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	done := make(chan error)
+	go func() {
+		_, err := New(li, arn).Invoke(ctx, nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+		var e *InvocationError
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, KindCancelled, e.Kind)
+	case <-time.After(time.Second):
+		t.Fatal("Invoke didn't return after the context was cancelled")
+	}
+}
+
+func TestInvokeAlreadyCancelled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda function shouldn't be invoked")
+		return nil, nil
+	})
+	_, err := New(li, arn).Invoke(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestInvokerGetters(t *testing.T) {
+	t.Parallel()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn)
+	assert.Equal(t, arn, invoker.ARN())
+	assert.Equal(t, lambda.InvocationTypeRequestResponse, invoker.InvocationType())
+	invoker = New(li, arn, AsEvent())
+	assert.Equal(t, lambda.InvocationTypeEvent, invoker.InvocationType())
+}
+
+func TestInvokeAsProcedureWithRequestEnvelopeHook(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var req router.Request
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		require.NoError(t, json.Unmarshal(i.Payload, &req))
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"body":"result"}`),
+		}, nil
+	})
+	invoker := New(li, arn, AsProcedure("Do", nil), WithRequestEnvelopeHook(func(req *router.Request) error {
+		assert.Equal(t, "Do", req.Procedure)
+		req.Body = json.RawMessage(`"hooked"`)
+		return nil
+	}))
+	result, err := invoker.Invoke(ctx, json.RawMessage(`"content"`))
+	require.NoError(t, err)
+	assert.Equal(t, `"result"`, string(result))
+	assert.Equal(t, `"hooked"`, string(req.Body))
+}
+
+func TestInvokeAsProcedureWithRequestEnvelopeHookError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda function shouldn't be invoked")
+		return nil, nil
+	})
+	hookErr := errors.New("rejected")
+	invoker := New(li, arn, AsProcedure("Do", nil), WithRequestEnvelopeHook(func(*router.Request) error {
+		return hookErr
+	}))
+	_, err := invoker.Invoke(ctx, json.RawMessage(`"content"`))
+	assert.Equal(t, hookErr, err)
+}
+
+func TestInvokeWithUserAgent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var userAgent string
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, opts ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		r := &awsreq.Request{
+			HTTPRequest: &http.Request{Header: http.Header{}},
+		}
+		r.HTTPRequest.Header.Set("User-Agent", "aws-sdk-go/1.44.280")
+		for _, opt := range opts {
+			opt(r)
+		}
+		r.Handlers.Build.Run(r)
+		userAgent = r.HTTPRequest.Header.Get("User-Agent")
+		return &lambda.InvokeOutput{}, nil
+	})
+	_, err := New(li, arn, WithUserAgent("my-service/1.0")).Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "aws-sdk-go/1.44.280 my-service/1.0", userAgent)
+}
+
+func TestBuildProcedureRequest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	built, err := BuildProcedureRequest("Do", json.RawMessage(`{"key":"value"}`))
+	require.NoError(t, err)
+	req := router.Request{}
+	require.NoError(t, json.Unmarshal(built, &req))
+	assert.Equal(t, "Do", req.Procedure)
+	assert.JSONEq(t, `{"key":"value"}`, string(req.Body))
+
+	var sent []byte
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		sent = i.Payload
+		return &lambda.InvokeOutput{}, nil
+	})
+	_, err = New(li, arn, AsProcedure("Do", nil)).Invoke(ctx, json.RawMessage(`{"key":"value"}`))
+	require.NoError(t, err)
+	assert.Equal(t, string(built), string(sent))
+}
+
+func TestInvokeDeadlineExceeded(t *testing.T) {
+	t.Parallel()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(ctx context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	_, err := New(li, arn, WithTimeout(10*time.Millisecond)).Invoke(context.Background(), nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, errors.Is(err, context.Canceled))
+	var e *InvocationError
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, KindTimeout, e.Kind)
+}
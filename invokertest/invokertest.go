@@ -0,0 +1,135 @@
+// Package invokertest provides a mock LambdaInvoker, for testing code which
+// depends on an invoker.Invoker.
+package invokertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// ErrNoResponse is returned by MockInvoker when it's invoked without a
+// response having been queued.
+var ErrNoResponse = errors.New("no response queued")
+
+type response struct {
+	output *lambda.InvokeOutput
+	err    error
+}
+
+// MockInvoker implements invoker.LambdaInvoker, recording each invocation and
+// responding with the responses queued, in order. It's safe for concurrent
+// use.
+type MockInvoker struct {
+	mu          sync.Mutex
+	responses   []response
+	invocations []*lambda.InvokeInput
+}
+
+// AppliesRequestOptions reports true, so a MockInvoker can stand in for a
+// *lambda.Lambda with Options which pass request options to it, such as
+// WithAssumeRole; the options themselves are ignored.
+func (m *MockInvoker) AppliesRequestOptions() bool {
+	return true
+}
+
+// InvokeWithContext records the input and returns the next queued response.
+func (m *MockInvoker) InvokeWithContext(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *input
+	copied.Payload = append([]byte(nil), input.Payload...)
+	m.invocations = append(m.invocations, &copied)
+	if len(m.responses) == 0 {
+		return nil, ErrNoResponse
+	}
+	rsp := m.responses[0]
+	m.responses = m.responses[1:]
+	return rsp.output, rsp.err
+}
+
+// Respond queues a response.
+func (m *MockInvoker) Respond(output *lambda.InvokeOutput, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses = append(m.responses, response{
+		output: output,
+		err:    err,
+	})
+}
+
+// RespondJSON queues a successful response with v marshaled to json as its
+// payload. It panics if v can't be marshaled.
+func (m *MockInvoker) RespondJSON(v interface{}) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("invokertest: failed to marshal response: %v", err))
+	}
+	m.Respond(&lambda.InvokeOutput{
+		Payload:    payload,
+		StatusCode: aws.Int64(http.StatusOK),
+	}, nil)
+}
+
+// RespondFunctionError queues a response reporting that the function failed
+// with message.
+func (m *MockInvoker) RespondFunctionError(message string, statusCode int64) {
+	payload, _ := json.Marshal(map[string]string{
+		"errorMessage": message,
+	})
+	m.Respond(&lambda.InvokeOutput{
+		FunctionError: aws.String("Unhandled"),
+		Payload:       payload,
+		StatusCode:    aws.Int64(statusCode),
+	}, nil)
+}
+
+// RespondError queues a response failing with err, as if the invocation
+// itself failed.
+func (m *MockInvoker) RespondError(err error) {
+	m.Respond(nil, err)
+}
+
+// Invocations returns the inputs of each invocation made so far.
+func (m *MockInvoker) Invocations() []*lambda.InvokeInput {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*lambda.InvokeInput(nil), m.invocations...)
+}
+
+// AssertPayload checks that the payload of the nth invocation (starting at 0)
+// is equivalent json to expected.
+func (m *MockInvoker) AssertPayload(t testing.TB, n int, expected string) bool {
+	t.Helper()
+	invocations := m.Invocations()
+	if n >= len(invocations) {
+		t.Errorf("invocation %d wasn't made, only %d invocations were", n, len(invocations))
+		return false
+	}
+	actual := invocations[n].Payload
+	if !equivalentJSON([]byte(expected), actual) {
+		t.Errorf("invocation %d payload mismatch:\nexpected: %s\nactual:   %s", n, expected, actual)
+		return false
+	}
+	return true
+}
+
+// equivalentJSON reports whether a and b decode to equal values, or are equal
+// byte for byte if either isn't json.
+func equivalentJSON(a, b []byte) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
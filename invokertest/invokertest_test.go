@@ -0,0 +1,48 @@
+package invokertest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	invoker "github.com/edstell/lambda-invoker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockInvoker(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	mock := &MockInvoker{}
+	mock.RespondJSON(map[string]string{"result": "value"})
+	mock.RespondFunctionError("failed", http.StatusOK)
+	inv := invoker.New(mock, "test-arn")
+
+	result, err := inv.Invoke(ctx, []byte(`{"key": "value"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"result":"value"}`, string(result))
+
+	_, err = inv.Invoke(ctx, []byte(`{"key":"other"}`))
+	require.Error(t, err)
+	var e *invoker.Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "failed", e.Error())
+
+	_, err = inv.Invoke(ctx, nil)
+	assert.ErrorIs(t, err, ErrNoResponse)
+
+	assert.Len(t, mock.Invocations(), 3)
+	mock.AssertPayload(t, 0, `{"key":"value"}`)
+	mock.AssertPayload(t, 1, `{"key":"other"}`)
+}
+
+func TestAssertPayloadMismatch(t *testing.T) {
+	t.Parallel()
+	mock := &MockInvoker{}
+	mock.RespondJSON(nil)
+	_, err := invoker.New(mock, "test-arn").Invoke(context.Background(), []byte(`{"key":"value"}`))
+	require.NoError(t, err)
+	assert.False(t, mock.AssertPayload(&testing.T{}, 0, `{"key":"other"}`))
+	assert.False(t, mock.AssertPayload(&testing.T{}, 1, `{}`))
+}
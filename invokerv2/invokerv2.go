@@ -0,0 +1,114 @@
+// Package invokerv2 adapts the aws-sdk-go-v2 lambda client so that it can be
+// used to invoke lambda functions with an invoker.Invoker, and all of the
+// Options which configure it.
+package invokerv2
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	awsv1 "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	lambdav1 "github.com/aws/aws-sdk-go/service/lambda"
+	invoker "github.com/edstell/lambda-invoker"
+)
+
+// LambdaInvoker abstracts the aws-sdk-go-v2 lambda client behind an interface,
+// this is to allow mocking the aws Lambda implementation.
+type LambdaInvoker interface {
+	Invoke(context.Context, *lambda.InvokeInput, ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
+// Adapter satisfies invoker.LambdaInvoker by translating each invocation to
+// and from the aws-sdk-go-v2 types.
+type Adapter struct {
+	li     LambdaInvoker
+	optFns []func(*lambda.Options)
+}
+
+var _ invoker.LambdaInvoker = (*Adapter)(nil)
+
+// Adapt wraps li in an Adapter. The optFns passed will be applied to every
+// invocation.
+func Adapt(li LambdaInvoker, optFns ...func(*lambda.Options)) *Adapter {
+	return &Adapter{
+		li:     li,
+		optFns: optFns,
+	}
+}
+
+// New initializes an invoker.Invoker which invokes lambda functions with the
+// aws-sdk-go-v2 client li.
+func New(li LambdaInvoker, arn string, opts ...invoker.Option) *invoker.Invoker {
+	return invoker.New(Adapt(li), arn, opts...)
+}
+
+// AppliesRequestOptions reports false; the aws-sdk-go request options aren't
+// supported by the v2 client.
+func (a *Adapter) AppliesRequestOptions() bool {
+	return false
+}
+
+// InvokeWithContext invokes the lambda function with the v2 client. The
+// aws-sdk-go request options aren't supported by the v2 client, so are
+// ignored; the Invoker warns when it's passed them, and options which rely on
+// them, such as invoker.WithAssumeRole, fail. Configure the v2 client, or pass
+// optFns to Adapt, instead. Errors returned by the v2 client are translated to awserr errors so
+// they can be classified by the invoker.
+func (a *Adapter) InvokeWithContext(ctx context.Context, input *lambdav1.InvokeInput, _ ...awsreq.Option) (*lambdav1.InvokeOutput, error) {
+	output, err := a.li.Invoke(ctx, &lambda.InvokeInput{
+		FunctionName:   input.FunctionName,
+		ClientContext:  input.ClientContext,
+		InvocationType: types.InvocationType(awsv1.StringValue(input.InvocationType)),
+		LogType:        types.LogType(awsv1.StringValue(input.LogType)),
+		Payload:        input.Payload,
+		Qualifier:      input.Qualifier,
+	}, a.optFns...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &lambdav1.InvokeOutput{
+		ExecutedVersion: output.ExecutedVersion,
+		FunctionError:   output.FunctionError,
+		LogResult:       output.LogResult,
+		Payload:         output.Payload,
+		StatusCode:      awsv1.Int64(int64(output.StatusCode)),
+	}, nil
+}
+
+// apiError is implemented by errors returned from an aws service.
+type apiError interface {
+	ErrorCode() string
+	ErrorMessage() string
+}
+
+// responseError is implemented by errors which have an http response.
+type responseError interface {
+	HTTPStatusCode() int
+	ServiceRequestID() string
+}
+
+// translateError converts an error returned by the v2 client into an awserr
+// error, retaining the original as the cause.
+func translateError(err error) error {
+	var aerr apiError
+	if !errors.As(err, &aerr) {
+		return err
+	}
+	e := awserr.New(aerr.ErrorCode(), aerr.ErrorMessage(), err)
+	var rerr responseError
+	if !errors.As(err, &rerr) {
+		return e
+	}
+	return awserr.NewRequestFailure(e, rerr.HTTPStatusCode(), rerr.ServiceRequestID())
+}
+
+// NewFromConfig initializes an invoker.Invoker with a lambda client built from
+// cfg, for callers who don't already have one.
+func NewFromConfig(cfg aws.Config, arn string, opts ...invoker.Option) *invoker.Invoker {
+	return New(lambda.NewFromConfig(cfg), arn, opts...)
+}
@@ -0,0 +1,114 @@
+package invokerv2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	invoker "github.com/edstell/lambda-invoker"
+	router "github.com/edstell/lambda-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type LambdaInvokerFunc func(context.Context, *lambda.InvokeInput, ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+
+func (f LambdaInvokerFunc) Invoke(ctx context.Context, i *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	return f(ctx, i, optFns...)
+}
+
+type apiErr struct{}
+
+func (apiErr) Error() string            { return "throttled" }
+func (apiErr) ErrorCode() string        { return "TooManyRequestsException" }
+func (apiErr) ErrorMessage() string     { return "slow down" }
+func (apiErr) HTTPStatusCode() int      { return http.StatusTooManyRequests }
+func (apiErr) ServiceRequestID() string { return "request-id" }
+
+func TestInvoke(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	body := json.RawMessage(`{"key":"value"}`)
+	output := []byte(`{"invoke":"result"}`)
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+		assert.Equal(t, arn, aws.ToString(i.FunctionName))
+		assert.Equal(t, types.InvocationTypeRequestResponse, i.InvocationType)
+		assert.Equal(t, string(body), string(i.Payload))
+		return &lambda.InvokeOutput{
+			Payload:    output,
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+	result, err := New(li, arn).Invoke(ctx, body)
+	require.NoError(t, err)
+	assert.Equal(t, string(output), string(result))
+}
+
+func TestInvokeAsProcedureWithError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+		req := &router.Request{}
+		if err := json.Unmarshal(i.Payload, req); err != nil {
+			return nil, err
+		}
+		assert.Equal(t, "Do", req.Procedure)
+		return &lambda.InvokeOutput{
+			Payload:    []byte(`{"error":"failed"}`),
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+	_, err := New(li, arn, invoker.AsProcedure("Do", func(e json.RawMessage) error {
+		return errors.New(string(e))
+	})).Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.Equal(t, `"failed"`, err.Error())
+}
+
+func TestInvokeWithAPIError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+		return nil, apiErr{}
+	})
+	_, err := New(li, arn).Invoke(ctx, nil)
+	require.Error(t, err)
+	var rerr awserr.RequestFailure
+	require.True(t, errors.As(err, &rerr))
+	assert.Equal(t, "TooManyRequestsException", rerr.Code())
+	assert.Equal(t, http.StatusTooManyRequests, rerr.StatusCode())
+	assert.Equal(t, apiErr{}, rerr.OrigErr())
+}
+
+func TestInvokeWithRequestOptions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+	var warnings []string
+	inv := New(li, arn, invoker.WithUserAgent("test"), invoker.WithWarningHandler(func(msg string) {
+		warnings = append(warnings, msg)
+	}))
+	for n := 0; n < 2; n++ {
+		_, err := inv.Invoke(ctx, nil, awsreq.WithAppendUserAgent("call"))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{
+		"request options are ignored by the lambda invoker for 'test-arn'",
+		"request options passed to invoke 'test-arn' are ignored by the lambda invoker",
+	}, warnings)
+}
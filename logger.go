@@ -0,0 +1,174 @@
+package invoker
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// InvokeLog describes an attempt to invoke a lambda function.
+type InvokeLog struct {
+	ARN string
+	// Attempt is the number of the attempt, starting at 1; it's only greater
+	// than 1 when retries are enabled.
+	Attempt int
+	// Delay is how long was waited before making the attempt; it's chosen by
+	// the backoff, or by the retry hint of a throttling error.
+	Delay   time.Duration
+	Latency time.Duration
+	// StatusCode is the status code of the attempt, or -1 if it isn't known.
+	StatusCode int64
+	// RequestID is the id aws assigned to the attempt, if known.
+	RequestID string
+	// Tags are returned by the extractor passed to WithTagExtractor, if any.
+	Tags map[string]string
+	// Err is the error the attempt failed with, if any. This is either the
+	// error returned by the LambdaInvoker or an Error returned by the
+	// function.
+	Err error
+	// Payload and Response are the payloads sent to, and returned by, the
+	// function; they're only populated if WithRedactor or WithPayloadLogging
+	// is provided, and have been redacted and truncated as configured.
+	Payload  json.RawMessage
+	Response json.RawMessage
+}
+
+// Logger implementations can log each attempt to invoke a lambda function.
+type Logger interface {
+	Log(InvokeLog)
+}
+
+// LoggerFunc adapts a func to the Logger interface.
+type LoggerFunc func(InvokeLog)
+
+// Log calls f.
+func (f LoggerFunc) Log(l InvokeLog) {
+	f(l)
+}
+
+// WithLogger returns an option which can be passed when initializing an
+// Invoker. If provided, l will be called after every attempt to invoke the
+// lambda function, whether it succeeded or not.
+func WithLogger(l Logger) Option {
+	return func(i *Invoker) {
+		i.logger = l
+	}
+}
+
+// WithWarningHandler returns an option which can be passed when initializing
+// an Invoker. If provided, warnings about how the Invoker was configured,
+// such as retries being disabled since the function isn't idempotent, are
+// passed to warn; otherwise they're discarded. Pass a func which writes to
+// log.Printf to have them logged.
+func WithWarningHandler(warn func(msg string)) Option {
+	return func(i *Invoker) {
+		i.warn = warn
+	}
+}
+
+// warnf formats a warning and passes it to the Invoker's warning handler, if
+// there is one.
+func (i *Invoker) warnf(format string, args ...interface{}) {
+	if i.warn == nil {
+		return
+	}
+	i.warn(fmt.Sprintf(format, args...))
+}
+
+// log passes a description of an attempt to invoke the lambda function to the
+// Logger, if there is one.
+func (i *Invoker) log(attempt int, delay, latency time.Duration, requestID string, tags map[string]string, input *lambda.InvokeInput, output *lambda.InvokeOutput, err error) {
+	if i.logger == nil {
+		return
+	}
+	l := InvokeLog{
+		ARN:        i.arn,
+		Attempt:    attempt,
+		Delay:      delay,
+		Latency:    latency,
+		StatusCode: -1,
+		RequestID:  requestID,
+		Tags:       tags,
+		Err:        err,
+	}
+	if err != nil {
+		l.StatusCode = errorStatusCode(err)
+	}
+	if output != nil {
+		if output.StatusCode != nil {
+			l.StatusCode = aws.Int64Value(output.StatusCode)
+		}
+		if output.FunctionError != nil {
+			l.Err = i.newFunctionError(output)
+		}
+	}
+	i.logPayloads(&l, input, output)
+	i.logger.Log(l)
+}
+
+// Truncated is appended to payloads which were truncated before being logged
+// because they were larger than the limit passed to WithPayloadLogging.
+const Truncated = "...[TRUNCATED]"
+
+// WithPayloadLogging returns an option which can be passed when initializing
+// an Invoker. If provided the payload sent to the lambda function, and the
+// payload it responds with, are given to the Logger in the InvokeLog; those
+// larger than maxBytes are truncated to their first maxBytes, followed by
+// Truncated, and logged as a JSON string so the InvokeLog remains valid JSON.
+// If maxBytes is 0 or less payloads are logged in full. If WithRedactor is
+// also provided payloads are redacted before they're truncated. By default
+// payloads aren't logged.
+func WithPayloadLogging(maxBytes int) Option {
+	return func(i *Invoker) {
+		i.payloadLogging = true
+		i.payloadLogLimit = maxBytes
+	}
+}
+
+// logPayloads populates the payloads of l from input and output, passed
+// through the redactor and truncated; nothing is populated if neither is
+// configured.
+func (i *Invoker) logPayloads(l *InvokeLog, input *lambda.InvokeInput, output *lambda.InvokeOutput) {
+	if i.redact == nil && !i.payloadLogging {
+		return
+	}
+	l.Payload = i.loggedPayload(input.Payload)
+	if output == nil {
+		return
+	}
+	l.Response = i.loggedPayload(output.Payload)
+	if e, ok := l.Err.(*Error); ok {
+		e.Payload = l.Response
+	}
+}
+
+// loggedPayload returns payload as it should be logged.
+func (i *Invoker) loggedPayload(payload json.RawMessage) json.RawMessage {
+	if i.redact != nil {
+		payload = i.redact(payload)
+	}
+	return truncatePayload(payload, i.payloadLogLimit)
+}
+
+// truncatePayload returns payload untouched if it isn't larger than
+// maxBytes, or maxBytes is 0 or less. Otherwise it's cut to at most maxBytes,
+// without splitting a UTF-8 encoded character, followed by Truncated; and
+// returned as a JSON string, since the cut payload isn't valid JSON.
+func truncatePayload(payload json.RawMessage, maxBytes int) json.RawMessage {
+	if maxBytes <= 0 || len(payload) <= maxBytes {
+		return payload
+	}
+	n := maxBytes
+	for n > 0 && !utf8.RuneStart(payload[n]) {
+		n--
+	}
+	truncated, err := json.Marshal(string(payload[:n]) + Truncated)
+	if err != nil {
+		return nil
+	}
+	return truncated
+}
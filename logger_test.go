@@ -0,0 +1,93 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithLogger(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	attempts := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, awserr.New(lambda.ErrCodeTooManyRequestsException, "slow down", nil)
+		}
+		return &lambda.InvokeOutput{
+			StatusCode: aws.Int64(http.StatusOK),
+		}, nil
+	})
+	var logs []InvokeLog
+	backoff := func(int) time.Duration {
+		return time.Millisecond
+	}
+	invoker := New(li, arn, WithRetry(2, backoff), WithLogger(LoggerFunc(func(l InvokeLog) {
+		logs = append(logs, l)
+	})))
+	_, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, arn, logs[0].ARN)
+	assert.Equal(t, 1, logs[0].Attempt)
+	assert.Equal(t, time.Duration(0), logs[0].Delay)
+	assert.Equal(t, int64(-1), logs[0].StatusCode)
+	assert.Error(t, logs[0].Err)
+	assert.Equal(t, 2, logs[1].Attempt)
+	assert.Equal(t, time.Millisecond, logs[1].Delay)
+	assert.Equal(t, int64(http.StatusOK), logs[1].StatusCode)
+	assert.NoError(t, logs[1].Err)
+}
+
+func TestInvokeWithPayloadLogging(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"id":1}`),
+		}, nil
+	})
+	var logged InvokeLog
+	logger := WithLogger(LoggerFunc(func(l InvokeLog) {
+		logged = l
+	}))
+
+	_, err := New(li, arn, logger).Invoke(ctx, []byte(`{"password":"hunter2"}`))
+	require.NoError(t, err)
+	assert.Nil(t, logged.Payload)
+	assert.Nil(t, logged.Response)
+
+	_, err = New(li, arn, logger, WithPayloadLogging(12)).Invoke(ctx, []byte(`{"password":"hunter2"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `"{\"password\":`+Truncated+`"`, string(logged.Payload))
+	assert.Equal(t, `{"id":1}`, string(logged.Response))
+
+	_, err = New(li, arn, logger, WithPayloadLogging(16), WithRedactor(DefaultRedactor)).Invoke(ctx, []byte(`{"password":"hunter2"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `"{\"password\":\"[RE`+Truncated+`"`, string(logged.Payload))
+	_, err = json.Marshal(logged)
+	require.NoError(t, err)
+
+	_, err = New(li, arn, logger, WithPayloadLogging(0)).Invoke(ctx, []byte(`{"password":"hunter2"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"password":"hunter2"}`, string(logged.Payload))
+}
+
+func TestTruncatePayload(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, `"\"é`+Truncated+`"`, string(truncatePayload([]byte(`"éé"`), 4)))
+	assert.Equal(t, `"\"`+Truncated+`"`, string(truncatePayload([]byte(`"éé"`), 2)))
+	assert.Equal(t, `"éé"`, string(truncatePayload([]byte(`"éé"`), 6)))
+}
@@ -0,0 +1,72 @@
+package invoker
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// WithLogTail returns an option which can be passed when initializing an
+// Invoker. If provided the last 4KB of the execution log of each invocation
+// will be requested, and passed to sink once decoded. The sink isn't called
+// if no log was returned.
+func WithLogTail(sink func(logs string)) Option {
+	return func(i *Invoker) {
+		i.inputMutators = append(i.inputMutators, func(_ context.Context, input *lambda.InvokeInput) error {
+			input.LogType = aws.String(lambda.LogTypeTail)
+			return nil
+		})
+		i.outputMutators = append(i.outputMutators, func(output *lambda.InvokeOutput) error {
+			if output.LogResult == nil {
+				return nil
+			}
+			logs, err := base64.StdEncoding.DecodeString(*output.LogResult)
+			if err != nil {
+				return err
+			}
+			sink(string(logs))
+			return nil
+		})
+	}
+}
+
+// recordReport populates the durations of m from the REPORT line lambda
+// writes at the end of the execution log of each invocation, if logs
+// contains one.
+func (m *Meta) recordReport(logs string) {
+	for _, line := range strings.Split(logs, "\n") {
+		if !strings.HasPrefix(line, "REPORT ") {
+			continue
+		}
+		for _, field := range strings.Split(line, "\t") {
+			name, value, ok := strings.Cut(strings.TrimSpace(field), ": ")
+			if !ok {
+				continue
+			}
+			switch name {
+			case "Duration":
+				m.Duration = parseReportDuration(value)
+			case "Billed Duration":
+				m.BilledDuration = parseReportDuration(value)
+			case "Init Duration":
+				m.InitDuration = parseReportDuration(value)
+				m.ColdStart = true
+			}
+		}
+	}
+}
+
+// parseReportDuration parses a duration in the format used by the REPORT
+// line, e.g. '12.34 ms'; it returns 0 if value can't be parsed.
+func parseReportDuration(value string) time.Duration {
+	ms, err := strconv.ParseFloat(strings.TrimSuffix(value, " ms"), 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -0,0 +1,47 @@
+package invoker
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithLogTail(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	logs := "START RequestId: 1\nEND RequestId: 1\n"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.Equal(t, lambda.LogTypeTail, *i.LogType)
+		return &lambda.InvokeOutput{
+			LogResult: aws.String(base64.StdEncoding.EncodeToString([]byte(logs))),
+		}, nil
+	})
+	var result string
+	invoker := New(li, arn, WithLogTail(func(l string) {
+		result = l
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, logs, result)
+}
+
+func TestInvokeWithLogTailMissing(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithLogTail(func(string) {
+		t.Fatal("sink should not be called")
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,116 @@
+package invoker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// Meta describes how an invocation was performed.
+type Meta struct {
+	// ExecutedVersion is the version of the function which was invoked.
+	ExecutedVersion string
+	// StatusCode is the status code of the invocation, or 0 if the lambda
+	// function wasn't invoked.
+	StatusCode int64
+	// LogTail is the decoded execution log, if it was requested.
+	LogTail string
+	// ProcedureStatusCode is the status code the procedure called by
+	// AsProcedure reported in its response, or 0 if it didn't report one.
+	ProcedureStatusCode int64
+	// RequestID is the id aws assigned to the request which invoked the
+	// function, if known. If the invocation was retried it's the id of the
+	// final attempt.
+	RequestID string
+	// ColdStart, Duration, BilledDuration and InitDuration are parsed from
+	// the REPORT line of the LogTail, so are only populated if it was
+	// requested (e.g. by WithLogTail). ColdStart is true if the function's
+	// execution environment was initialized to handle the invocation, in which
+	// case InitDuration is how long that took.
+	ColdStart      bool
+	Duration       time.Duration
+	BilledDuration time.Duration
+	InitDuration   time.Duration
+}
+
+type metaKey struct{}
+
+// InvokeWithMeta invokes the lambda function in the same way as Invoke, also
+// returning metadata describing the invocation. The metadata is returned even
+// if the invocation fails, but will be empty if the function wasn't invoked;
+// for example if a Middleware returned early.
+func (i *Invoker) InvokeWithMeta(ctx context.Context, body json.RawMessage, opts ...awsreq.Option) (json.RawMessage, Meta, error) {
+	return i.call(ctx, body, &callConfig{requestOptions: opts})
+}
+
+// recordMeta populates the Meta carried by ctx, if any, from output.
+func recordMeta(ctx context.Context, output *lambda.InvokeOutput) {
+	meta, ok := ctx.Value(metaKey{}).(*Meta)
+	if !ok || output == nil {
+		return
+	}
+	meta.ExecutedVersion = aws.StringValue(output.ExecutedVersion)
+	meta.StatusCode = aws.Int64Value(output.StatusCode)
+	if output.LogResult != nil {
+		if logs, err := base64.StdEncoding.DecodeString(*output.LogResult); err == nil {
+			meta.LogTail = string(logs)
+			meta.recordReport(meta.LogTail)
+		}
+	}
+}
+
+// captureRequestID returns a request option which stores the id of the
+// request in id once it completes, whether it succeeded or not.
+func captureRequestID(id *string) awsreq.Option {
+	return func(r *awsreq.Request) {
+		r.Handlers.Complete.PushBack(func(r *awsreq.Request) {
+			*id = r.RequestID
+		})
+	}
+}
+
+// errorRequestID returns the id of the request which failed with err, if it
+// has one.
+func errorRequestID(err error) string {
+	if rerr, ok := err.(awserr.RequestFailure); ok {
+		return rerr.RequestID()
+	}
+	return ""
+}
+
+// procedureStatus returns the status code reported in the statusCode field
+// of a procedure's response envelope, or 0 if it doesn't have one. It's
+// decoded as JSON regardless of the codec, since the field is optional.
+func procedureStatus(payload json.RawMessage) int64 {
+	if len(payload) == 0 {
+		return 0
+	}
+	status := struct {
+		StatusCode int64 `json:"statusCode"`
+	}{}
+	if err := json.Unmarshal(payload, &status); err != nil {
+		return 0
+	}
+	return status.StatusCode
+}
+
+// recordProcedureStatus sets the ProcedureStatusCode of the Meta carried by
+// ctx, if any.
+func recordProcedureStatus(ctx context.Context, status int64) {
+	if meta, ok := ctx.Value(metaKey{}).(*Meta); ok {
+		meta.ProcedureStatusCode = status
+	}
+}
+
+// recordRequestID sets the RequestID of the Meta carried by ctx, if any.
+func recordRequestID(ctx context.Context, id string) {
+	if meta, ok := ctx.Value(metaKey{}).(*Meta); ok {
+		meta.RequestID = id
+	}
+}
@@ -0,0 +1,161 @@
+package invoker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithMeta(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	logs := "REPORT RequestId: 1"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			ExecutedVersion: aws.String("7"),
+			StatusCode:      aws.Int64(http.StatusOK),
+			LogResult:       aws.String(base64.StdEncoding.EncodeToString([]byte(logs))),
+			Payload:         json.RawMessage(`"result"`),
+		}, nil
+	})
+	invoker := New(li, arn, WithLogTail(func(string) {}))
+	result, meta, err := invoker.InvokeWithMeta(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `"result"`, string(result))
+	assert.Equal(t, Meta{
+		ExecutedVersion: "7",
+		StatusCode:      http.StatusOK,
+		LogTail:         logs,
+	}, meta)
+}
+
+func TestInvokeWithMetaFunctionError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			ExecutedVersion: aws.String("7"),
+			FunctionError:   aws.String("Unhandled"),
+			StatusCode:      aws.Int64(http.StatusOK),
+		}, nil
+	})
+	invoker := New(li, arn)
+	_, meta, err := invoker.InvokeWithMeta(ctx, nil)
+	require.Error(t, err)
+	assert.Equal(t, "7", meta.ExecutedVersion)
+}
+
+func TestInvokeWithMetaRequestID(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, opts ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		r := &awsreq.Request{}
+		for _, opt := range opts {
+			opt(r)
+		}
+		r.RequestID = "request-id"
+		r.Handlers.Complete.Run(r)
+		return &lambda.InvokeOutput{}, nil
+	})
+	var logged string
+	invoker := New(li, arn, WithLogger(LoggerFunc(func(l InvokeLog) {
+		logged = l.RequestID
+	})))
+	_, meta, err := invoker.InvokeWithMeta(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "request-id", meta.RequestID)
+	assert.Equal(t, "request-id", logged)
+}
+
+func TestInvokeWithMetaRequestIDError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return nil, awserr.NewRequestFailure(awserr.New(lambda.ErrCodeServiceException, "unavailable", nil), http.StatusServiceUnavailable, "failed-request-id")
+	})
+	_, meta, err := New(li, arn).InvokeWithMeta(ctx, nil)
+	require.Error(t, err)
+	assert.Equal(t, "failed-request-id", meta.RequestID)
+}
+
+func TestInvokeWithMetaColdStart(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var logs string
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			LogResult: aws.String(base64.StdEncoding.EncodeToString([]byte(logs))),
+		}, nil
+	})
+	invoker := New(li, arn, WithLogTail(func(string) {}))
+
+	logs = "START RequestId: 1 Version: $LATEST\n" +
+		"END RequestId: 1\n" +
+		"REPORT RequestId: 1\tDuration: 12.50 ms\tBilled Duration: 13 ms\tMemory Size: 128 MB\tMax Memory Used: 64 MB\tInit Duration: 150.25 ms\t\n"
+	_, meta, err := invoker.InvokeWithMeta(ctx, nil)
+	require.NoError(t, err)
+	assert.True(t, meta.ColdStart)
+	assert.Equal(t, 12500*time.Microsecond, meta.Duration)
+	assert.Equal(t, 13*time.Millisecond, meta.BilledDuration)
+	assert.Equal(t, 150250*time.Microsecond, meta.InitDuration)
+
+	logs = "REPORT RequestId: 2\tDuration: 2.00 ms\tBilled Duration: 2 ms\tMemory Size: 128 MB\tMax Memory Used: 64 MB\t\n"
+	_, meta, err = invoker.InvokeWithMeta(ctx, nil)
+	require.NoError(t, err)
+	assert.False(t, meta.ColdStart)
+	assert.Equal(t, 2*time.Millisecond, meta.Duration)
+	assert.Equal(t, time.Duration(0), meta.InitDuration)
+}
+
+func TestInvokeWithMetaProcedureStatusCode(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var payload []byte
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			StatusCode: aws.Int64(http.StatusOK),
+			Payload:    payload,
+		}, nil
+	})
+	procedureErr := errors.New("not found")
+	invoker := New(li, arn, AsProcedure("Do", func(json.RawMessage) error {
+		return procedureErr
+	}))
+
+	payload = []byte(`{"statusCode":201,"body":"created"}`)
+	result, meta, err := invoker.InvokeWithMeta(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `"created"`, string(result))
+	assert.Equal(t, int64(201), meta.ProcedureStatusCode)
+	assert.Equal(t, int64(http.StatusOK), meta.StatusCode)
+
+	payload = []byte(`{"statusCode":404,"error":"not found"}`)
+	_, meta, err = invoker.InvokeWithMeta(ctx, nil)
+	assert.ErrorIs(t, err, procedureErr)
+	e := &Error{}
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, int64(http.StatusNotFound), e.StatusCode)
+	assert.Equal(t, int64(http.StatusNotFound), meta.ProcedureStatusCode)
+
+	payload = []byte(`{"error":"not found"}`)
+	_, meta, err = invoker.InvokeWithMeta(ctx, nil)
+	assert.Equal(t, procedureErr, err)
+	assert.Equal(t, int64(0), meta.ProcedureStatusCode)
+}
@@ -0,0 +1,102 @@
+package invoker
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// Metrics implementations can record how invocations of lambda functions are
+// performing.
+type Metrics interface {
+	// ObserveLatency is called with the duration of every attempt to invoke
+	// the lambda function named by arn.
+	ObserveLatency(arn string, d time.Duration)
+	// IncError is called whenever an attempt to invoke the lambda function
+	// named by arn fails, or the function returns an error. If the status code
+	// isn't known it will be -1.
+	IncError(arn string, statusCode int64)
+}
+
+// TaggedMetrics implementations can record how invocations of lambda
+// functions are performing along with the tags returned by the extractor
+// passed to WithTagExtractor. If the Metrics passed to WithMetrics implement
+// TaggedMetrics its methods are called in place of those of Metrics.
+type TaggedMetrics interface {
+	Metrics
+	ObserveLatencyTagged(arn string, d time.Duration, tags map[string]string)
+	IncErrorTagged(arn string, statusCode int64, tags map[string]string)
+}
+
+// nopMetrics is the default Metrics implementation, it discards everything.
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveLatency(string, time.Duration) {}
+func (nopMetrics) IncError(string, int64)               {}
+
+// WithMetrics returns an option which can be passed when initializing an
+// Invoker. If provided, m will be used to record the latency and errors of
+// each invocation.
+func WithMetrics(m Metrics) Option {
+	return func(i *Invoker) {
+		i.metrics = m
+	}
+}
+
+// observe records the outcome of an attempt to invoke the lambda function.
+func (i *Invoker) observe(d time.Duration, tags map[string]string, output *lambda.InvokeOutput, err error) {
+	observeLatency, incError := i.metrics.ObserveLatency, i.metrics.IncError
+	if tagged, ok := i.metrics.(TaggedMetrics); ok {
+		observeLatency = func(arn string, d time.Duration) {
+			tagged.ObserveLatencyTagged(arn, d, tags)
+		}
+		incError = func(arn string, statusCode int64) {
+			tagged.IncErrorTagged(arn, statusCode, tags)
+		}
+	}
+	observeLatency(i.arn, d)
+	if i.histogram != nil {
+		i.histogram.observe(d)
+	}
+	if err != nil {
+		incError(i.arn, errorStatusCode(err))
+		return
+	}
+	if output != nil && output.FunctionError != nil {
+		incError(i.arn, i.errorStatus(output))
+	}
+}
+
+// WithTagExtractor returns an option which can be passed when initializing an
+// Invoker. If provided, extract will be called with the context of each
+// attempt to invoke the lambda function, and the tags it returns passed to the
+// Logger in the InvokeLog, and to the Metrics if they implement TaggedMetrics.
+// It isn't called if there's no hook to pass the tags to.
+func WithTagExtractor(extract func(context.Context) map[string]string) Option {
+	return func(i *Invoker) {
+		i.extractTags = extract
+	}
+}
+
+// tags returns the tags of an attempt made with ctx, or nil if there's no
+// extractor or nothing to pass them to.
+func (i *Invoker) tags(ctx context.Context) map[string]string {
+	if i.extractTags == nil {
+		return nil
+	}
+	if _, ok := i.metrics.(TaggedMetrics); !ok && i.logger == nil {
+		return nil
+	}
+	return i.extractTags(ctx)
+}
+
+// errorStatusCode returns the status code of the response which caused err,
+// or -1 if there wasn't one.
+func errorStatusCode(err error) int64 {
+	if rerr, ok := err.(awserr.RequestFailure); ok {
+		return int64(rerr.StatusCode())
+	}
+	return -1
+}
@@ -0,0 +1,116 @@
+package invoker
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMetrics struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    []int64
+}
+
+func (m *recordingMetrics) ObserveLatency(_ string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latencies = append(m.latencies, d)
+}
+
+func (m *recordingMetrics) IncError(_ string, statusCode int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors = append(m.errors, statusCode)
+}
+
+func TestInvokeWithMetrics(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	attempts := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, awserr.NewRequestFailure(awserr.New(lambda.ErrCodeServiceException, "unavailable", nil), http.StatusServiceUnavailable, "request-id")
+		}
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			StatusCode:    aws.Int64(http.StatusOK),
+		}, nil
+	})
+	m := &recordingMetrics{}
+	invoker := New(li, arn, WithMetrics(m), WithRetry(2, noBackoff))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.Len(t, m.latencies, 2)
+	assert.Equal(t, []int64{http.StatusServiceUnavailable, http.StatusOK}, m.errors)
+}
+
+type taggedMetrics struct {
+	recordingMetrics
+	tags []map[string]string
+}
+
+func (m *taggedMetrics) ObserveLatencyTagged(arn string, d time.Duration, tags map[string]string) {
+	m.ObserveLatency(arn, d)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tags = append(m.tags, tags)
+}
+
+func (m *taggedMetrics) IncErrorTagged(arn string, statusCode int64, tags map[string]string) {
+	m.IncError(arn, statusCode)
+}
+
+type tenantKey struct{}
+
+func TestInvokeWithTagExtractor(t *testing.T) {
+	t.Parallel()
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			StatusCode:    aws.Int64(http.StatusOK),
+		}, nil
+	})
+	extract := func(ctx context.Context) map[string]string {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return map[string]string{"tenant": tenant}
+	}
+	m := &taggedMetrics{}
+	var logged InvokeLog
+	invoker := New(li, arn, WithMetrics(m), WithTagExtractor(extract), WithLogger(LoggerFunc(func(l InvokeLog) {
+		logged = l
+	})))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.Equal(t, []map[string]string{{"tenant": "acme"}}, m.tags)
+	assert.Equal(t, []int64{http.StatusOK}, m.errors)
+	assert.Equal(t, map[string]string{"tenant": "acme"}, logged.Tags)
+}
+
+func TestInvokeWithTagExtractorNoHooks(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithMetrics(&recordingMetrics{}), WithTagExtractor(func(context.Context) map[string]string {
+		t.Fatal("tags shouldn't be extracted")
+		return nil
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,37 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// InvokeFunc has the same signature as Invoker.Invoke, without request
+// options.
+type InvokeFunc func(ctx context.Context, body json.RawMessage) (json.RawMessage, error)
+
+// Middleware wraps an InvokeFunc, allowing behaviour to be added around each
+// invocation; for example logging, caching or retries. The Middleware can
+// inspect and modify both the body passed to and the result returned by next,
+// or not call it at all.
+type Middleware func(next InvokeFunc) InvokeFunc
+
+// WithMiddleware returns an option which can be passed when initializing an
+// Invoker. Each Middleware will wrap invocations of the lambda function, the
+// first Middleware passed being the outermost. Calling WithMiddleware again
+// adds further Middleware inside those already configured.
+func WithMiddleware(m ...Middleware) Option {
+	return func(i *Invoker) {
+		i.middleware = chain(i.middleware, chain(m...))
+	}
+}
+
+// chain composes the Middleware m into a single Middleware, with m[0] being the
+// outermost.
+func chain(m ...Middleware) Middleware {
+	return func(next InvokeFunc) InvokeFunc {
+		for j := len(m) - 1; j >= 0; j-- {
+			next = m[j](next)
+		}
+		return next
+	}
+}
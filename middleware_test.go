@@ -0,0 +1,65 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithMiddleware(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.Equal(t, `"body"`, string(i.Payload))
+		return &lambda.InvokeOutput{
+			Payload: json.RawMessage(`"result"`),
+		}, nil
+	})
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next InvokeFunc) InvokeFunc {
+			return func(ctx context.Context, body json.RawMessage) (json.RawMessage, error) {
+				calls = append(calls, name+" before")
+				rsp, err := next(ctx, body)
+				calls = append(calls, name+" after")
+				return rsp, err
+			}
+		}
+	}
+	invoker := New(li, arn, WithMiddleware(record("outer"), record("middle")), WithMiddleware(record("inner")))
+	result, err := invoker.Invoke(ctx, json.RawMessage(`"body"`))
+	require.NoError(t, err)
+	assert.Equal(t, `"result"`, string(result))
+	assert.Equal(t, []string{
+		"outer before",
+		"middle before",
+		"inner before",
+		"inner after",
+		"middle after",
+		"outer after",
+	}, calls)
+}
+
+func TestInvokeWithShortCircuitMiddleware(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda should not be invoked")
+		return nil, nil
+	})
+	invoker := New(li, arn, WithMiddleware(func(InvokeFunc) InvokeFunc {
+		return func(context.Context, json.RawMessage) (json.RawMessage, error) {
+			return json.RawMessage(`"cached"`), nil
+		}
+	}))
+	result, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `"cached"`, string(result))
+}
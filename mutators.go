@@ -0,0 +1,103 @@
+package invoker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// ErrMutatorPanic is returned, wrapped with the panic's value and stack
+// trace, when an input or output mutator panics. Panics raised by the other
+// functions called while invoking the lambda function are returned in the
+// same way: preconditions, payload migrations, output inspectors, defaults on
+// error, idempotency key functions, key canonicalizers, Loggers, Metrics and
+// tag extractors.
+var ErrMutatorPanic = errors.New("mutator panicked")
+
+// AddInputMutator returns an option which can be passed when initializing an
+// Invoker. Input mutators are called in the order they were added, before
+// each invocation, and can modify the InvokeInput being sent. Returning an
+// error aborts the invocation.
+func AddInputMutator(mutate func(*lambda.InvokeInput) error) Option {
+	return func(i *Invoker) {
+		i.inputMutators = append(i.inputMutators, func(_ context.Context, input *lambda.InvokeInput) error {
+			return mutate(input)
+		})
+	}
+}
+
+// AddOutputMutator returns an option which can be passed when initializing an
+// Invoker. Output mutators are called after each invocation and can modify
+// the InvokeOutput received. They're called in the reverse order to which they
+// were added, so an Option which wraps the input payload will unwrap the
+// output payload in the matching position. Returning an error aborts the
+// invocation.
+func AddOutputMutator(mutate func(*lambda.InvokeOutput) error) Option {
+	return func(i *Invoker) {
+		i.outputMutators = append(i.outputMutators, mutate)
+	}
+}
+
+// mutateInput applies each of the input mutators to input, in order. If one
+// panics the panic is recovered and returned as an ErrMutatorPanic.
+func (i *Invoker) mutateInput(ctx context.Context, input *lambda.InvokeInput) (err error) {
+	defer recoverMutator(&err)
+	for _, mutate := range i.inputMutators {
+		if err := mutate(ctx, input); err != nil {
+			return err
+		}
+	}
+	return i.MutateInput(input)
+}
+
+// mutateOutput applies each of the output mutators to output, in reverse
+// order, once the payload has been decompressed and the response decoder
+// applied. The status code of a procedure's response is recorded in the Meta
+// carried by ctx, if any, before the mutators unwrap it. If a mutator panics
+// the panic is recovered and returned as an ErrMutatorPanic.
+func (i *Invoker) mutateOutput(ctx context.Context, output *lambda.InvokeOutput) (err error) {
+	defer recoverMutator(&err)
+	if i.decompressResponse && output.FunctionError == nil {
+		payload, err := decompressResponse(output.Payload)
+		if err != nil {
+			return err
+		}
+		output.Payload = payload
+	}
+	if i.responseDecoder != nil && len(output.Payload) > 0 && output.FunctionError == nil {
+		payload, err := i.responseDecoder(output.Payload)
+		if err != nil {
+			return err
+		}
+		output.Payload = payload
+	}
+	if i.procedure != "" && output.FunctionError == nil {
+		recordProcedureStatus(ctx, procedureStatus(output.Payload))
+	}
+	if err := i.MutateOutput(output); err != nil {
+		return err
+	}
+	for j := len(i.outputMutators) - 1; j >= 0; j-- {
+		if err := i.outputMutators[j](output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverMutator recovers a panic raised by a mutator, setting err to an
+// ErrMutatorPanic describing it. It must be deferred.
+func recoverMutator(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%w: %v\n%s", ErrMutatorPanic, r, debug.Stack())
+	}
+}
+
+// guard calls f, returning a panic raised by it as an ErrMutatorPanic.
+func guard(f func() error) (err error) {
+	defer recoverMutator(&err)
+	return f()
+}
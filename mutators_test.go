@@ -0,0 +1,159 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	router "github.com/edstell/lambda-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithMutators(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	procedure := "Do"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.Equal(t, "prod", *i.Qualifier)
+		req := &router.Request{}
+		require.NoError(t, json.Unmarshal(i.Payload, req))
+		assert.Equal(t, procedure, req.Procedure)
+		return &lambda.InvokeOutput{
+			Payload:         json.RawMessage(`{"body":{"key":"value"}}`),
+			ExecutedVersion: aws.String("1"),
+		}, nil
+	})
+	var calls []string
+	invoker := New(li, arn,
+		AddInputMutator(func(i *lambda.InvokeInput) error {
+			calls = append(calls, "input")
+			i.Qualifier = aws.String("prod")
+			return nil
+		}),
+		AddOutputMutator(func(o *lambda.InvokeOutput) error {
+			calls = append(calls, "output")
+			assert.Equal(t, `{"key":"value"}`, string(o.Payload))
+			return nil
+		}),
+		AsProcedure(procedure, func(e json.RawMessage) error {
+			return errors.New(string(e))
+		}),
+	)
+	result, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"key":"value"}`, string(result))
+	assert.Equal(t, []string{"input", "output"}, calls)
+}
+
+func TestInvokeWithMutatorError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda should not be invoked")
+		return nil, nil
+	})
+	invoker := New(li, arn, AddInputMutator(func(*lambda.InvokeInput) error {
+		return assert.AnError
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	assert.Equal(t, assert.AnError, err)
+}
+
+func TestInvokeWithPanickingMutator(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	invocations := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		invocations++
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, AddInputMutator(func(*lambda.InvokeInput) error {
+		panic("input")
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	assert.ErrorIs(t, err, ErrMutatorPanic)
+	assert.Contains(t, err.Error(), "input")
+	assert.Contains(t, err.Error(), "mutators_test.go")
+	assert.Equal(t, 0, invocations)
+
+	invoker = New(li, arn)
+	invoker.MutateOutput = func(*lambda.InvokeOutput) error {
+		panic("output")
+	}
+	_, err = invoker.Invoke(ctx, nil)
+	assert.ErrorIs(t, err, ErrMutatorPanic)
+	assert.Contains(t, err.Error(), "output")
+	assert.Equal(t, 1, invocations)
+}
+
+type panickingMetrics struct {
+	nopMetrics
+}
+
+func (panickingMetrics) ObserveLatency(string, time.Duration) {
+	panic("metrics")
+}
+
+func TestInvokeWithPanickingHooks(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	for name, opts := range map[string][]Option{
+		"precondition": {WithPrecondition(func(context.Context, json.RawMessage) (bool, json.RawMessage, error) {
+			panic("precondition")
+		})},
+		"payload migration": {WithPayloadMigration(func(json.RawMessage) (json.RawMessage, error) {
+			panic("payload migration")
+		})},
+		"output inspector": {WithOutputInspector(func(*lambda.InvokeOutput) {
+			panic("output inspector")
+		})},
+		"default on error": {
+			AddInputMutator(func(*lambda.InvokeInput) error {
+				return assert.AnError
+			}),
+			WithDefaultOnError(func(error) (json.RawMessage, bool) {
+				panic("default on error")
+			}),
+		},
+		"idempotency key": {WithIdempotency(func(json.RawMessage) string {
+			panic("idempotency key")
+		}, NewMemoryCache(), time.Minute)},
+		"key canonicalizer": {
+			WithCache(NewMemoryCache(), time.Minute),
+			WithKeyCanonicalizer(func(json.RawMessage) json.RawMessage {
+				panic("key canonicalizer")
+			}),
+		},
+		"logger": {WithLogger(LoggerFunc(func(InvokeLog) {
+			panic("logger")
+		}))},
+		"metrics": {WithMetrics(panickingMetrics{})},
+		"tag extractor": {
+			WithLogger(LoggerFunc(func(InvokeLog) {})),
+			WithTagExtractor(func(context.Context) map[string]string {
+				panic("tag extractor")
+			}),
+		},
+	} {
+		name, opts := name, opts
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := New(li, arn, opts...).Invoke(ctx, json.RawMessage(`{}`))
+			assert.ErrorIs(t, err, ErrMutatorPanic)
+			assert.Contains(t, err.Error(), name)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// InvokePaged invokes the lambda function repeatedly, starting with initial as
+// the body, collecting each response. After each invocation next is called
+// with the response to build the body of the following invocation; once it
+// returns done no further invocations are made. If an invocation or next
+// fails, or ctx is done before the next invocation is made, the responses
+// collected so far are returned along with the error.
+func (i *Invoker) InvokePaged(ctx context.Context, initial json.RawMessage, next func(resp json.RawMessage) (json.RawMessage, bool, error)) ([]json.RawMessage, error) {
+	var responses []json.RawMessage
+	body := initial
+	for {
+		if err := ctx.Err(); err != nil {
+			return responses, err
+		}
+		resp, err := i.Invoke(ctx, body)
+		if err != nil {
+			return responses, err
+		}
+		responses = append(responses, resp)
+		var done bool
+		body, done, err = next(resp)
+		if err != nil {
+			return responses, err
+		}
+		if done {
+			return responses, nil
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedLambda returns a page holding the cursor it was sent, along with the
+// next cursor until the last page is reached.
+func pagedLambda(t *testing.T, last int) LambdaInvokerFunc {
+	return LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		req := struct {
+			Cursor int `json:"cursor"`
+		}{}
+		require.NoError(t, json.Unmarshal(input.Payload, &req))
+		page := `{"page":` + strconv.Itoa(req.Cursor)
+		if req.Cursor < last {
+			page += `,"next":` + strconv.Itoa(req.Cursor+1)
+		}
+		return &lambda.InvokeOutput{
+			Payload: []byte(page + `}`),
+		}, nil
+	})
+}
+
+func nextPage(resp json.RawMessage) (json.RawMessage, bool, error) {
+	page := struct {
+		Next *int `json:"next"`
+	}{}
+	if err := json.Unmarshal(resp, &page); err != nil {
+		return nil, false, err
+	}
+	if page.Next == nil {
+		return nil, true, nil
+	}
+	return []byte(`{"cursor":` + strconv.Itoa(*page.Next) + `}`), false, nil
+}
+
+func TestInvokePaged(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	responses, err := New(pagedLambda(t, 2), arn).InvokePaged(ctx, []byte(`{"cursor":0}`), nextPage)
+	require.NoError(t, err)
+	require.Len(t, responses, 3)
+	assert.JSONEq(t, `{"page":0,"next":1}`, string(responses[0]))
+	assert.JSONEq(t, `{"page":2}`, string(responses[2]))
+}
+
+func TestInvokePagedNextError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	nextErr := errors.New("bad cursor")
+	responses, err := New(pagedLambda(t, 2), arn).InvokePaged(ctx, []byte(`{"cursor":0}`), func(json.RawMessage) (json.RawMessage, bool, error) {
+		return nil, false, nextErr
+	})
+	assert.Equal(t, nextErr, err)
+	assert.Len(t, responses, 1)
+}
+
+func TestInvokePagedCancelled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	arn := "test-arn"
+	responses, err := New(pagedLambda(t, 10), arn).InvokePaged(ctx, []byte(`{"cursor":0}`), func(resp json.RawMessage) (json.RawMessage, bool, error) {
+		cancel()
+		return nextPage(resp)
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Len(t, responses, 1)
+}
@@ -0,0 +1,73 @@
+package invoker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// ErrEmptyResponse is returned by Invoke if WithRequireNonEmptyResponse was
+// provided and the lambda function succeeded without returning a payload.
+var ErrEmptyResponse = errors.New("lambda function returned an empty response")
+
+// defaultEmptyPayload is sent in place of an empty body.
+var defaultEmptyPayload = json.RawMessage(`null`)
+
+// WithEmptyPayload returns an option which can be passed when initializing an
+// Invoker. If provided, payload is sent in place of a nil or empty body,
+// rather than 'null'; for example '{}' for functions which expect an object.
+func WithEmptyPayload(payload json.RawMessage) Option {
+	return func(i *Invoker) {
+		i.emptyPayload = payload
+	}
+}
+
+// normalizePayload returns body, or the Invoker's empty payload if body is
+// nil or empty; so both are sent to the lambda function as valid JSON.
+func (i *Invoker) normalizePayload(body json.RawMessage) json.RawMessage {
+	if len(body) > 0 {
+		return body
+	}
+	return i.emptyPayload
+}
+
+// WithPayloadMigration returns an option which can be passed when initializing
+// an Invoker. If provided, migrate will be called with the payload of each
+// invocation once all input mutators (including MutateInput) have been
+// applied, and its result sent in place of the payload; so the payload is
+// wrapped by AsProcedure, if provided, before it's migrated. It's called
+// before the payload size is checked. If migrate returns an error the lambda
+// function isn't invoked, and the error is returned.
+func WithPayloadMigration(migrate func(json.RawMessage) (json.RawMessage, error)) Option {
+	return func(i *Invoker) {
+		i.migrate = migrate
+	}
+}
+
+// migratePayload applies the payload migration, if any, to input.
+func (i *Invoker) migratePayload(input *lambda.InvokeInput) (err error) {
+	if i.migrate == nil {
+		return nil
+	}
+	defer recoverMutator(&err)
+	payload, err := i.migrate(input.Payload)
+	if err != nil {
+		return fmt.Errorf("migrating payload: %w", err)
+	}
+	input.Payload = payload
+	return nil
+}
+
+// WithRequireNonEmptyResponse returns an option which can be passed when
+// initializing an Invoker. If provided Invoke will return ErrEmptyResponse if
+// the lambda function succeeds but returns an empty payload, rather than
+// returning the empty payload; it's checked once the output mutators have
+// been applied, so a procedure called by AsProcedure which responds without a
+// body is rejected too. Only 'RequestResponse' invocations are affected.
+func WithRequireNonEmptyResponse() Option {
+	return func(i *Invoker) {
+		i.requireResponse = true
+	}
+}
@@ -0,0 +1,103 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeEmptyPayload(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var payloads []string
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		payloads = append(payloads, string(i.Payload))
+		return &lambda.InvokeOutput{}, nil
+	})
+	for _, invoker := range []*Invoker{
+		New(li, arn),
+		New(li, arn, WithEmptyPayload(json.RawMessage(`{}`))),
+	} {
+		for _, body := range []json.RawMessage{nil, {}, json.RawMessage(`{}`)} {
+			_, err := invoker.Invoke(ctx, body)
+			require.NoError(t, err)
+		}
+	}
+	assert.Equal(t, []string{"null", "null", "{}", "{}", "{}", "{}"}, payloads)
+}
+
+func TestInvokeWithPayloadMigration(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var sent []byte
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		sent = input.Payload
+		return &lambda.InvokeOutput{}, nil
+	})
+	migrate := func(payload json.RawMessage) (json.RawMessage, error) {
+		v1 := struct {
+			Name string `json:"name"`
+		}{}
+		if err := json.Unmarshal(payload, &v1); err != nil {
+			return nil, err
+		}
+		if v1.Name == "" {
+			return nil, errors.New("name is required")
+		}
+		return json.Marshal(map[string]interface{}{
+			"version": 2,
+			"user":    map[string]string{"name": v1.Name},
+		})
+	}
+	invoker := New(li, arn, WithPayloadMigration(migrate), WithPayloadLimits(40, DefaultAsyncPayloadLimit))
+	_, err := invoker.Invoke(ctx, json.RawMessage(`{"name":"a"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version":2,"user":{"name":"a"}}`, string(sent))
+
+	sent = nil
+	_, err = invoker.Invoke(ctx, json.RawMessage(`{}`))
+	assert.EqualError(t, err, "migrating payload: name is required")
+	assert.Nil(t, sent)
+
+	// The migrated payload is checked against the size limit.
+	_, err = invoker.Invoke(ctx, json.RawMessage(`{"name":"`+strings.Repeat("a", 16)+`"}`))
+	assert.ErrorIs(t, err, ErrPayloadTooLarge)
+	assert.Nil(t, sent)
+}
+
+func TestInvokeWithRequireNonEmptyResponse(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var payload []byte
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: payload,
+		}, nil
+	})
+	result, err := New(li, arn).Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, result)
+
+	invoker := New(li, arn, WithRequireNonEmptyResponse())
+	_, err = invoker.Invoke(ctx, nil)
+	assert.ErrorIs(t, err, ErrEmptyResponse)
+
+	payload = []byte(`{}`)
+	result, err = invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(result))
+
+	// The payload is checked once the procedure's response is unwrapped.
+	_, err = New(li, arn, AsProcedure("Do", nil), WithRequireNonEmptyResponse()).Invoke(ctx, nil)
+	assert.ErrorIs(t, err, ErrEmptyResponse)
+}
@@ -0,0 +1,40 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// ErrNotProcedure is returned by InvokeProcedure if the Invoker wasn't
+// initialized with AsProcedure, or one of its variants.
+var ErrNotProcedure = errors.New("invoker isn't configured to call a procedure")
+
+// procedureKey is the context key the procedure passed to InvokeProcedure is
+// carried under. It's keyed on the Invoker, so other Invokers called with the
+// context, for example by Middleware, aren't affected by the override.
+type procedureKey struct {
+	invoker *Invoker
+}
+
+// procedureOverride returns the procedure passed to InvokeProcedure, if ctx
+// carries one for i.
+func (i *Invoker) procedureOverride(ctx context.Context) (string, bool) {
+	procedure, ok := ctx.Value(procedureKey{invoker: i}).(string)
+	return procedure, ok
+}
+
+// InvokeProcedure invokes the lambda function in the same way as Invoke, but
+// calls the named procedure in place of the one the Invoker was initialized
+// with by AsProcedure; so one Invoker, and its error unmarshaling, can be
+// used to call each of the procedures a function routes. The Invoker must
+// have been initialized with AsProcedure, or one of its variants, otherwise
+// ErrNotProcedure is returned. The override only applies to i; other Invokers
+// called with the context passed to hooks, for example by Middleware, call
+// their own procedure.
+func (i *Invoker) InvokeProcedure(ctx context.Context, procedure string, body json.RawMessage) (json.RawMessage, error) {
+	if i.procedure == "" {
+		return nil, ErrNotProcedure
+	}
+	return i.Invoke(context.WithValue(ctx, procedureKey{invoker: i}, procedure), body)
+}
@@ -0,0 +1,106 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	router "github.com/edstell/lambda-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeProcedure(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		req := router.Request{}
+		require.NoError(t, json.Unmarshal(input.Payload, &req))
+		if req.Procedure == "Fail" {
+			return &lambda.InvokeOutput{
+				Payload: []byte(`{"error":"failed"}`),
+			}, nil
+		}
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"body":"` + req.Procedure + `"}`),
+		}, nil
+	})
+	procedureErr := errors.New("procedure failed")
+	invoker := New(li, arn, AsProcedure("Get", func(json.RawMessage) error {
+		return procedureErr
+	}))
+	result, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `"Get"`, string(result))
+	result, err = invoker.InvokeProcedure(ctx, "Put", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `"Put"`, string(result))
+	_, err = invoker.InvokeProcedure(ctx, "Fail", nil)
+	assert.Equal(t, procedureErr, err)
+}
+
+func TestInvokeProcedureNotConfigured(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda shouldn't be invoked")
+		return nil, nil
+	})
+	_, err := New(li, arn).InvokeProcedure(ctx, "Do", nil)
+	assert.ErrorIs(t, err, ErrNotProcedure)
+}
+
+func TestInvokeProcedureNested(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	var procedures []string
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		req := router.Request{}
+		require.NoError(t, json.Unmarshal(input.Payload, &req))
+		procedures = append(procedures, *input.FunctionName+"."+req.Procedure)
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"body":{}}`),
+		}, nil
+	})
+	inner := New(li, "inner", AsProcedure("InnerProc", nil))
+	outer := New(li, "outer", AsProcedure("Get", nil), WithMiddleware(func(next InvokeFunc) InvokeFunc {
+		return func(ctx context.Context, body json.RawMessage) (json.RawMessage, error) {
+			if _, err := inner.Invoke(ctx, nil); err != nil {
+				return nil, err
+			}
+			return next(ctx, body)
+		}
+	}))
+	_, err := outer.InvokeProcedure(ctx, "Put", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"inner.InnerProc", "outer.Put"}, procedures)
+}
+
+func TestInvokeProcedureWithIdempotency(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		req := router.Request{}
+		require.NoError(t, json.Unmarshal(input.Payload, &req))
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"body":"` + req.Procedure + `"}`),
+		}, nil
+	})
+	invoker := New(li, arn, AsProcedure("Get", nil), WithIdempotency(func(payload json.RawMessage) string {
+		return string(payload)
+	}, NewMemoryCache(), time.Minute))
+	body := []byte(`{"id":"a"}`)
+	result, err := invoker.InvokeProcedure(ctx, "Get", body)
+	require.NoError(t, err)
+	assert.Equal(t, `"Get"`, string(result))
+	result, err = invoker.InvokeProcedure(ctx, "Delete", body)
+	require.NoError(t, err)
+	assert.Equal(t, `"Delete"`, string(result))
+}
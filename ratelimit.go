@@ -0,0 +1,32 @@
+package invoker
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// WithRateLimit returns an option which can be passed when initializing an
+// Invoker. If provided invocations will be limited to r per second, with
+// bursts of up to burst invocations; Invoke will block until an invocation is
+// permitted, or the context passed is done.
+func WithRateLimit(r rate.Limit, burst int) Option {
+	return func(i *Invoker) {
+		i.limiter = rate.NewLimiter(r, burst)
+	}
+}
+
+// wait blocks until the rate limit, if any, permits an invocation. If ctx is
+// done first its error is returned.
+func (i *Invoker) wait(ctx context.Context) error {
+	if i.limiter == nil {
+		return nil
+	}
+	if err := i.limiter.Wait(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package invoker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestInvokeWithRateLimit(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	interval := 20 * time.Millisecond
+	invoker := New(li, arn, WithRateLimit(rate.Every(interval), 1))
+	start := time.Now()
+	for j := 0; j < 3; j++ {
+		_, err := invoker.Invoke(ctx, nil)
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(2*interval))
+}
+
+func TestInvokeWithRateLimitCancelled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithRateLimit(rate.Every(time.Hour), 1))
+	_, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	cancel()
+	_, err = invoker.Invoke(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
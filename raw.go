@@ -0,0 +1,22 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// InvokeRaw invokes the lambda function in the same way as Invoke, but if the
+// function returns an error its payload is returned as is along with the
+// FunctionError reported by lambda (e.g. 'Unhandled'), rather than being
+// converted to an Error. functionError is empty if the function succeeded.
+// Errors invoking the function are returned as by Invoke, except that they
+// aren't passed to the handler provided by WithDefaultOnError.
+func (i *Invoker) InvokeRaw(ctx context.Context, body json.RawMessage) (payload json.RawMessage, functionError string, err error) {
+	payload, _, err = i.call(ctx, body, &callConfig{skipDefaultOnError: true})
+	e := &Error{}
+	if errors.As(err, &e) && e.functionError != "" {
+		return e.Payload, e.functionError, nil
+	}
+	return payload, "", err
+}
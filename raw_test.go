@@ -0,0 +1,78 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeRaw(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var output *lambda.InvokeOutput
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return output, nil
+	})
+	invoker := New(li, arn)
+
+	output = &lambda.InvokeOutput{
+		Payload: []byte(`{"result":true}`),
+	}
+	payload, functionError, err := invoker.InvokeRaw(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, functionError)
+	assert.Equal(t, `{"result":true}`, string(payload))
+
+	output = &lambda.InvokeOutput{
+		FunctionError: aws.String("Unhandled"),
+		Payload:       []byte(`{"errorMessage":"failed"}`),
+	}
+	payload, functionError, err = invoker.InvokeRaw(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Unhandled", functionError)
+	assert.Equal(t, `{"errorMessage":"failed"}`, string(payload))
+}
+
+func TestInvokeRawError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	invokeErr := errors.New("failed")
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return nil, invokeErr
+	})
+	payload, functionError, err := New(li, arn).InvokeRaw(ctx, nil)
+	assert.ErrorIs(t, err, invokeErr)
+	assert.Empty(t, functionError)
+	assert.Nil(t, payload)
+}
+
+func TestInvokeRawWithDefaultOnError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       []byte(`{"errorMessage":"failed"}`),
+		}, nil
+	})
+	invoker := New(li, arn, WithDefaultOnError(func(error) (json.RawMessage, bool) {
+		return []byte(`"default"`), true
+	}))
+	payload, functionError, err := invoker.InvokeRaw(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Unhandled", functionError)
+	assert.Equal(t, `{"errorMessage":"failed"}`, string(payload))
+	payload, err = invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `"default"`, string(payload))
+}
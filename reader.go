@@ -0,0 +1,44 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// InvokeReader invokes the lambda function in the same way as Invoke, with
+// the payload read from r. Lambda requires the whole payload up front, so r is
+// read into memory before invoking; but reading is aborted with
+// ErrPayloadTooLarge as soon as more than the payload limit for the Invoker's
+// invocation type has been read, rather than once r is exhausted. Mutators,
+// such as AsProcedure, may grow the payload after it's read, so it's checked
+// against the limit again before it's sent.
+func (i *Invoker) InvokeReader(ctx context.Context, r io.Reader) (json.RawMessage, error) {
+	body, err := i.readPayload(r)
+	if err != nil {
+		return nil, err
+	}
+	return i.Invoke(ctx, body)
+}
+
+// readPayload reads r into memory, returning ErrPayloadTooLarge once it
+// exceeds the payload limit.
+func (i *Invoker) readPayload(r io.Reader) (json.RawMessage, error) {
+	limit := i.payloadLimit(i.invocationType)
+	if limit <= 0 {
+		body, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload: %w", err)
+		}
+		return body, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload: %w", err)
+	}
+	if len(body) > limit {
+		return nil, fmt.Errorf("%w: more than the %s limit of %d bytes was read", ErrPayloadTooLarge, i.invocationType, limit)
+	}
+	return body, nil
+}
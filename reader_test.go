@@ -0,0 +1,71 @@
+package invoker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeReader(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: input.Payload,
+		}, nil
+	})
+	result, err := New(li, arn).InvokeReader(ctx, strings.NewReader(`{"key":"value"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"key":"value"}`, string(result))
+}
+
+// endlessReader fails the test if more than max bytes are read from it.
+type endlessReader struct {
+	t    *testing.T
+	read int
+	max  int
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	r.read += len(p)
+	if r.read > r.max {
+		r.t.Fatal("read past the payload limit")
+	}
+	return copy(p, bytes.Repeat([]byte(" "), len(p))), nil
+}
+
+func TestInvokeReaderTooLarge(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda shouldn't be invoked")
+		return nil, nil
+	})
+	invoker := New(li, arn, WithPayloadLimits(16, 16))
+	_, err := invoker.InvokeReader(ctx, &endlessReader{t: t, max: 1024})
+	assert.ErrorIs(t, err, ErrPayloadTooLarge)
+}
+
+func TestInvokeReaderError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda shouldn't be invoked")
+		return nil, nil
+	})
+	failure := errors.New("broken pipe")
+	_, err := New(li, arn).InvokeReader(ctx, io.MultiReader(strings.NewReader(`{`), iotest.ErrReader(failure)))
+	assert.ErrorIs(t, err, failure)
+}
@@ -0,0 +1,67 @@
+package invoker
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Redacted is the value RedactKeys replaces sensitive values with.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactor replaces the values of commonly sensitive keys.
+var DefaultRedactor = RedactKeys("password", "secret", "token", "authorization", "apikey", "api_key", "ssn", "email", "phone")
+
+// WithRedactor returns an option which can be passed when initializing an
+// Invoker. If provided the payload sent to the lambda function, and the
+// payload it responds with, are passed through redact before being given to
+// the Logger in the InvokeLog; this includes the Payload of any Error logged.
+// What's sent to, and returned from, the function isn't affected. Payloads
+// are only logged if a redactor is provided, or WithPayloadLogging is; if
+// both are, payloads are redacted before they're truncated.
+func WithRedactor(redact func(json.RawMessage) json.RawMessage) Option {
+	return func(i *Invoker) {
+		i.redact = redact
+	}
+}
+
+// RedactKeys returns a redactor which replaces the value of any object key
+// matching one of keys, ignoring case, at any depth with Redacted. Payloads
+// which aren't valid JSON are dropped entirely.
+func RedactKeys(keys ...string) func(json.RawMessage) json.RawMessage {
+	sensitive := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		sensitive[strings.ToLower(key)] = true
+	}
+	return func(payload json.RawMessage) json.RawMessage {
+		if len(payload) == 0 {
+			return payload
+		}
+		var v interface{}
+		if err := json.Unmarshal(payload, &v); err != nil {
+			return nil
+		}
+		redacted, err := json.Marshal(redactValue(v, sensitive))
+		if err != nil {
+			return nil
+		}
+		return redacted
+	}
+}
+
+func redactValue(v interface{}, sensitive map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if sensitive[strings.ToLower(key)] {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = redactValue(value, sensitive)
+		}
+	case []interface{}:
+		for n, value := range v {
+			v[n] = redactValue(value, sensitive)
+		}
+	}
+	return v
+}
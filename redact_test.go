@@ -0,0 +1,83 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeWithRedactor(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.JSONEq(t, `{"user":{"email":"a@example.com"}}`, string(input.Payload))
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"token":"abc","id":1}`),
+		}, nil
+	})
+	var logged InvokeLog
+	invoker := New(li, arn, WithRedactor(DefaultRedactor), WithLogger(LoggerFunc(func(l InvokeLog) {
+		logged = l
+	})))
+	result, err := invoker.Invoke(ctx, []byte(`{"user":{"email":"a@example.com"}}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"token":"abc","id":1}`, string(result))
+	assert.JSONEq(t, `{"user":{"email":"[REDACTED]"}}`, string(logged.Payload))
+	assert.JSONEq(t, `{"token":"[REDACTED]","id":1}`, string(logged.Response))
+}
+
+func TestInvokeWithRedactorFunctionError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       []byte(`{"errorMessage":"failed","password":"hunter2"}`),
+		}, nil
+	})
+	var logged InvokeLog
+	invoker := New(li, arn, WithRedactor(RedactKeys("Password")), WithLogger(LoggerFunc(func(l InvokeLog) {
+		logged = l
+	})))
+	_, err := invoker.Invoke(ctx, nil)
+	returned := &Error{}
+	require.True(t, errors.As(err, &returned))
+	assert.JSONEq(t, `{"errorMessage":"failed","password":"hunter2"}`, string(returned.Payload))
+	loggedErr := &Error{}
+	require.True(t, errors.As(logged.Err, &loggedErr))
+	assert.JSONEq(t, `{"errorMessage":"failed","password":"[REDACTED]"}`, string(loggedErr.Payload))
+}
+
+func TestInvokeWithoutRedactor(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{}`),
+		}, nil
+	})
+	var logged InvokeLog
+	invoker := New(li, arn, WithLogger(LoggerFunc(func(l InvokeLog) {
+		logged = l
+	})))
+	_, err := invoker.Invoke(ctx, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Nil(t, logged.Payload)
+	assert.Nil(t, logged.Response)
+}
+
+func TestRedactKeysInvalidJSON(t *testing.T) {
+	t.Parallel()
+	assert.Nil(t, DefaultRedactor(json.RawMessage(`not json`)))
+	assert.Equal(t, json.RawMessage(nil), DefaultRedactor(nil))
+}
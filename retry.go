@@ -0,0 +1,199 @@
+package invoker
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// BackoffFunc returns how long to wait before retrying an invocation, given
+// the number of attempts which have been made so far.
+type BackoffFunc func(attempt int) time.Duration
+
+// ExponentialBackoff doubles the wait between each attempt, starting at 100ms
+// and capped at 10s.
+func ExponentialBackoff(attempt int) time.Duration {
+	const (
+		base = 100 * time.Millisecond
+		max  = 10 * time.Second
+	)
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > 8 {
+		return max
+	}
+	d := base << (attempt - 1)
+	if d > max {
+		return max
+	}
+	return d
+}
+
+// ExponentialBackoffWithJitter waits a random duration of up to that returned
+// by ExponentialBackoff between each attempt, so that callers which were
+// throttled together don't all retry at once.
+func ExponentialBackoffWithJitter(attempt int) time.Duration {
+	return time.Duration(rand.Int63n(int64(ExponentialBackoff(attempt)) + 1))
+}
+
+// retryPolicy describes how many times an invocation should be attempted,
+// and how long to wait between attempts.
+type retryPolicy struct {
+	maxAttempts int
+	backoff     BackoffFunc
+}
+
+// WithRetry returns an option which can be passed when initializing an
+// Invoker. If provided invocations which fail with a retryable error
+// (throttling, or a 5xx/429 status code) will be attempted up to maxAttempts
+// times, waiting between each attempt as determined by backoff. If backoff is
+// nil ExponentialBackoffWithJitter is used. When lambda throttles an attempt
+// and says how long to wait before retrying, that's waited instead of the
+// backoff. Function errors aren't retried since
+// they're returned by the lambda function itself.
+func WithRetry(maxAttempts int, backoff BackoffFunc) Option {
+	return func(i *Invoker) {
+		if maxAttempts < 1 {
+			maxAttempts = 1
+		}
+		if backoff == nil {
+			backoff = ExponentialBackoffWithJitter
+		}
+		i.retry = retryPolicy{
+			maxAttempts: maxAttempts,
+			backoff:     backoff,
+		}
+	}
+}
+
+// Idempotent returns an option which can be passed when initializing an
+// Invoker, declaring whether invoking the lambda function more than once has
+// the same effect as invoking it once. If idempotent is false retries are
+// disabled, even if WithRetry is provided, and a warning is passed to the
+// warning handler the first time Invoke is called. If Idempotent isn't provided retries are made as
+// configured by WithRetry.
+func Idempotent(idempotent bool) Option {
+	return func(i *Invoker) {
+		i.idempotent = &idempotent
+	}
+}
+
+// retryPolicy returns the retryPolicy invocations should be made with,
+// disabling retries if the function isn't idempotent.
+func (i *Invoker) retryPolicy() retryPolicy {
+	if i.idempotent == nil || *i.idempotent || i.retry.maxAttempts <= 1 {
+		return i.retry
+	}
+	i.warnNotIdempotent.Do(func() {
+		i.warnf("retries are disabled for '%s' since it isn't idempotent", i.arn)
+	})
+	return retryPolicy{maxAttempts: 1}
+}
+
+// do calls invoke until it succeeds, returns an error which can't be retried
+// or the maximum number of attempts have been made. It won't wait for the
+// next attempt if the context would be done before it's made. invoke is passed
+// how long was waited before the attempt.
+func (p retryPolicy) do(ctx context.Context, invoke func(attempt int, delay time.Duration) (*lambda.InvokeOutput, error)) (*lambda.InvokeOutput, error) {
+	var wait time.Duration
+	for attempt := 1; ; attempt++ {
+		output, err := invoke(attempt, wait)
+		if attempt >= p.maxAttempts || !retryable(output, err) {
+			return output, err
+		}
+		wait = p.backoff(attempt)
+		if hint, ok := retryAfter(err); ok {
+			wait = hint
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return output, err
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for d, returning the context's error if it's done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RetryOnError returns an option which can be passed when initializing an
+// Invoker. If provided, errors returned by the output mutators, including
+// those unmarshaled from a procedure's response by AsProcedure, are passed to
+// retry; if it returns true the lambda function is invoked again, as
+// configured by WithRetry, sharing its maximum number of attempts and
+// backoff. This allows application errors which are known to be transient to
+// be retried. Since the function has already been executed, errors are only
+// retried if Idempotent(true) is also provided; other errors are returned
+// immediately.
+func RetryOnError(retry func(error) bool) Option {
+	return func(i *Invoker) {
+		i.retryOnError = retry
+	}
+}
+
+// retryError reports whether err, returned by the output mutators after
+// attempts invocations, should be retried.
+func (i *Invoker) retryError(policy retryPolicy, attempts int, err error) bool {
+	if i.retryOnError == nil || i.idempotent == nil || !*i.idempotent {
+		return false
+	}
+	return attempts < policy.maxAttempts && i.retryOnError(err)
+}
+
+// retryable reports whether the result of an invocation indicates a transient
+// failure which might succeed if attempted again.
+func retryable(output *lambda.InvokeOutput, err error) bool {
+	if err != nil {
+		if awsreq.IsErrorThrottle(err) {
+			return true
+		}
+		if rerr, ok := err.(awserr.RequestFailure); ok {
+			return retryableStatus(int64(rerr.StatusCode()))
+		}
+		if aerr, ok := err.(awserr.Error); ok {
+			return aerr.Code() == lambda.ErrCodeTooManyRequestsException
+		}
+		return false
+	}
+	if output == nil || output.FunctionError != nil {
+		return false
+	}
+	return retryableStatus(aws.Int64Value(output.StatusCode))
+}
+
+// retryAfter returns how long lambda asked to be waited before retrying, if
+// err is a throttling error which says.
+func retryAfter(err error) (time.Duration, bool) {
+	terr := &lambda.TooManyRequestsException{}
+	if !errors.As(err, &terr) || terr.RetryAfterSeconds == nil {
+		return 0, false
+	}
+	seconds, perr := strconv.Atoi(*terr.RetryAfterSeconds)
+	if perr != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+func retryableStatus(code int64) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
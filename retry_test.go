@@ -0,0 +1,274 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noBackoff(int) time.Duration {
+	return 0
+}
+
+func TestInvokeWithRetry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	output := []byte(`{"invoke":"result"}`)
+	attempts := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, awserr.New(lambda.ErrCodeTooManyRequestsException, "slow down", nil)
+		}
+		return &lambda.InvokeOutput{
+			Payload: output,
+		}, nil
+	})
+	invoker := New(li, arn, WithRetry(3, noBackoff))
+	result, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, string(output), string(result))
+	assert.Equal(t, 3, attempts)
+}
+
+func TestInvokeWithRetryExhausted(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	attempts := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		attempts++
+		return nil, awserr.NewRequestFailure(awserr.New(lambda.ErrCodeServiceException, "unavailable", nil), http.StatusServiceUnavailable, "request-id")
+	})
+	invoker := New(li, arn, WithRetry(2, noBackoff))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestInvokeWithRetryNotIdempotent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	attempts := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		attempts++
+		return nil, awserr.New(lambda.ErrCodeTooManyRequestsException, "slow down", nil)
+	})
+	var warnings []string
+	invoker := New(li, arn, WithRetry(3, noBackoff), Idempotent(false), WithWarningHandler(func(msg string) {
+		warnings = append(warnings, msg)
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, attempts)
+	_, err = invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, []string{"retries are disabled for 'test-arn' since it isn't idempotent"}, warnings)
+
+	invoker = New(li, arn, WithRetry(3, noBackoff), Idempotent(true))
+	_, err = invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.Equal(t, 5, attempts)
+}
+
+func TestInvokeWithRetryFunctionError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	attempts := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		attempts++
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			StatusCode:    aws.Int64(http.StatusOK),
+			Payload:       json.RawMessage(`{}`),
+		}, nil
+	})
+	invoker := New(li, arn, WithRetry(3, noBackoff))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestInvokeWithRetryCancelled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		cancel()
+		return nil, awserr.New(lambda.ErrCodeTooManyRequestsException, "slow down", nil)
+	})
+	invoker := New(li, arn, WithRetry(3, func(int) time.Duration {
+		return time.Minute
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, 100*time.Millisecond, ExponentialBackoff(1))
+	assert.Equal(t, 200*time.Millisecond, ExponentialBackoff(2))
+	assert.Equal(t, 400*time.Millisecond, ExponentialBackoff(3))
+	assert.Equal(t, 10*time.Second, ExponentialBackoff(100))
+}
+
+func TestInvokeRetryStopsWhenCancelled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	arn := "test-arn"
+	calls := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		calls++
+		cancel()
+		return nil, awserr.New(lambda.ErrCodeTooManyRequestsException, "slow down", nil)
+	})
+	invoker := New(li, arn, WithRetry(3, func(int) time.Duration {
+		return 0
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
+
+func TestInvokeWithRetryAfterHint(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	arn := "test-arn"
+	attempts := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, &lambda.TooManyRequestsException{
+				RetryAfterSeconds: aws.String("0"),
+			}
+		}
+		return &lambda.InvokeOutput{}, nil
+	})
+	// The backoff would outlast the context, so the invocation is only
+	// retried if the hint is used instead.
+	invoker := New(li, arn, WithRetry(2, func(int) time.Duration {
+		return time.Minute
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+	d, ok := retryAfter(&lambda.TooManyRequestsException{RetryAfterSeconds: aws.String("3")})
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, d)
+	_, ok = retryAfter(&lambda.TooManyRequestsException{})
+	assert.False(t, ok)
+	_, ok = retryAfter(&lambda.TooManyRequestsException{RetryAfterSeconds: aws.String("soon")})
+	assert.False(t, ok)
+	_, ok = retryAfter(awserr.New(lambda.ErrCodeTooManyRequestsException, "slow down", nil))
+	assert.False(t, ok)
+}
+
+func TestExponentialBackoffWithJitter(t *testing.T) {
+	t.Parallel()
+	for attempt := 1; attempt < 10; attempt++ {
+		d := ExponentialBackoffWithJitter(attempt)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, ExponentialBackoff(attempt))
+	}
+}
+
+var errLockContended = errors.New("lock contended")
+
+func TestInvokeRetryOnError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	unmarshalError := func(e json.RawMessage) error {
+		if string(e) == `"LockContended"` {
+			return errLockContended
+		}
+		return errors.New(string(e))
+	}
+	isLockContended := func(err error) bool {
+		return errors.Is(err, errLockContended)
+	}
+	for name, tc := range map[string]struct {
+		failures []string
+		opts     []Option
+		attempts int
+		err      bool
+	}{
+		"retried": {
+			failures: []string{`"LockContended"`, `"LockContended"`},
+			opts:     []Option{Idempotent(true)},
+			attempts: 3,
+		},
+		"exhausted": {
+			failures: []string{`"LockContended"`, `"LockContended"`, `"LockContended"`},
+			opts:     []Option{Idempotent(true)},
+			attempts: 3,
+			err:      true,
+		},
+		"not matching": {
+			failures: []string{`"NotFound"`},
+			opts:     []Option{Idempotent(true)},
+			attempts: 1,
+			err:      true,
+		},
+		"not idempotent": {
+			failures: []string{`"LockContended"`},
+			attempts: 1,
+			err:      true,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			attempts := 0
+			li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+				attempts++
+				if attempts <= len(tc.failures) {
+					return &lambda.InvokeOutput{
+						Payload: []byte(`{"error":` + tc.failures[attempts-1] + `}`),
+					}, nil
+				}
+				return &lambda.InvokeOutput{
+					Payload: []byte(`{"body":"locked"}`),
+				}, nil
+			})
+			var logged []int
+			opts := append([]Option{
+				AsProcedure("Lock", unmarshalError),
+				WithRetry(3, noBackoff),
+				RetryOnError(isLockContended),
+				WithLogger(LoggerFunc(func(l InvokeLog) {
+					logged = append(logged, l.Attempt)
+				})),
+			}, tc.opts...)
+			result, err := New(li, arn, opts...).Invoke(ctx, nil)
+			assert.Equal(t, tc.attempts, attempts)
+			assert.Len(t, logged, tc.attempts)
+			assert.Equal(t, tc.attempts, logged[len(logged)-1])
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, `"locked"`, string(result))
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package invoker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// SchemaError is returned if a payload doesn't match the JSON Schema it's
+// expected to.
+type SchemaError struct {
+	// Violations describe each part of the payload which is invalid, prefixed
+	// with its location as a JSON pointer.
+	Violations []string
+}
+
+// Error lists the violations.
+func (e *SchemaError) Error() string {
+	return "payload doesn't match schema: " + strings.Join(e.Violations, "; ")
+}
+
+// WithResponseSchema returns an option which can be passed when initializing
+// an Invoker. If provided the payload returned by the lambda function will be
+// validated against the JSON Schema schema, returning a SchemaError if it
+// doesn't match. Empty payloads and function errors aren't validated. Since
+// output mutators are called in reverse order, it should be passed before
+// AsProcedure to validate the body of the procedure's response. If schema
+// can't be compiled Invoke will return an error.
+func WithResponseSchema(schema []byte) Option {
+	return func(i *Invoker) {
+		compiled, err := compileSchema(schema)
+		if err != nil {
+			i.optionErr = fmt.Errorf("compiling response schema: %w", err)
+			return
+		}
+		i.outputMutators = append(i.outputMutators, func(output *lambda.InvokeOutput) error {
+			if len(output.Payload) == 0 || output.FunctionError != nil {
+				return nil
+			}
+			return validateSchema(compiled, output.Payload)
+		})
+	}
+}
+
+// WithRequestSchema returns an option which can be passed when initializing an
+// Invoker. If provided the payload of each invocation will be validated
+// against the JSON Schema schema before the lambda function is invoked,
+// returning a SchemaError if it doesn't match. Empty and 'null' payloads
+// aren't validated. Since input mutators are called in order, it should be
+// passed before AsProcedure to validate the body rather than the
+// router.Request. If schema can't be compiled Invoke will return an error.
+func WithRequestSchema(schema []byte) Option {
+	return func(i *Invoker) {
+		compiled, err := compileSchema(schema)
+		if err != nil {
+			i.optionErr = fmt.Errorf("compiling request schema: %w", err)
+			return
+		}
+		i.inputMutators = append(i.inputMutators, func(_ context.Context, input *lambda.InvokeInput) error {
+			if len(input.Payload) == 0 || bytes.Equal(input.Payload, defaultEmptyPayload) {
+				return nil
+			}
+			return validateSchema(compiled, input.Payload)
+		})
+	}
+}
+
+// compileSchema compiles the JSON Schema schema.
+func compileSchema(schema []byte) (*jsonschema.Schema, error) {
+	const url = "schema.json"
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, bytes.NewReader(schema)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(url)
+}
+
+// validateSchema checks payload matches schema, returning a SchemaError
+// listing the violations if not.
+func validateSchema(schema *jsonschema.Schema, payload json.RawMessage) error {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Errorf("decoding payload: %w", err)
+	}
+	err := schema.Validate(v)
+	if err == nil {
+		return nil
+	}
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return err
+	}
+	var violations []string
+	for _, e := range verr.BasicOutput().Errors {
+		if e.Error == "" || strings.HasPrefix(e.Error, "doesn't validate with") {
+			continue
+		}
+		location := e.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		violations = append(violations, location+": "+e.Error)
+	}
+	return &SchemaError{Violations: violations}
+}
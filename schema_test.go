@@ -0,0 +1,112 @@
+package invoker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchema = []byte(`{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"age": {"type": "integer", "minimum": 0}
+	},
+	"required": ["name"]
+}`)
+
+func TestInvokeWithResponseSchema(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var payload []byte
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: payload,
+		}, nil
+	})
+	invoker := New(li, arn, WithResponseSchema(testSchema))
+
+	payload = []byte(`{"name":"lambda","age":3}`)
+	result, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, string(payload), string(result))
+
+	payload = nil
+	_, err = invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+
+	payload = []byte(`{"age":-1}`)
+	_, err = invoker.Invoke(ctx, nil)
+	var e *SchemaError
+	require.True(t, errors.As(err, &e))
+	assert.Len(t, e.Violations, 2)
+	assert.Contains(t, err.Error(), "/age")
+	assert.Contains(t, err.Error(), "name")
+}
+
+func TestInvokeWithResponseSchemaFunctionError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       []byte(`{"errorMessage":"failed"}`),
+		}, nil
+	})
+	_, err := New(li, arn, WithResponseSchema(testSchema)).Invoke(ctx, nil)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+}
+
+func TestNewValidatedInvalidResponseSchema(t *testing.T) {
+	t.Parallel()
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	_, err := NewValidated(li, "my-function", WithResponseSchema([]byte(`{"type":`)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "response schema")
+}
+
+func TestInvokeWithRequestSchema(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	invoked := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		invoked++
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithRequestSchema(testSchema))
+
+	_, err := invoker.Invoke(ctx, []byte(`{"name":"lambda","age":3}`))
+	require.NoError(t, err)
+
+	_, err = invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, invoked)
+
+	_, err = invoker.Invoke(ctx, []byte(`{"age":-1}`))
+	var e *SchemaError
+	require.True(t, errors.As(err, &e))
+	assert.Len(t, e.Violations, 2)
+	assert.Equal(t, 2, invoked)
+}
+
+func TestNewValidatedInvalidRequestSchema(t *testing.T) {
+	t.Parallel()
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	_, err := NewValidated(li, "my-function", WithRequestSchema([]byte(`{"type":`)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "request schema")
+}
@@ -0,0 +1,58 @@
+package invoker
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+const (
+	// DefaultSyncPayloadLimit is the largest payload, in bytes, lambda accepts
+	// for a 'RequestResponse' invocation.
+	DefaultSyncPayloadLimit = 6 * 1024 * 1024
+	// DefaultAsyncPayloadLimit is the largest payload, in bytes, lambda accepts
+	// for an 'Event' invocation.
+	DefaultAsyncPayloadLimit = 256 * 1024
+)
+
+// ErrPayloadTooLarge is returned if the payload of an invocation is larger
+// than lambda will accept; the function isn't invoked.
+var ErrPayloadTooLarge = errors.New("payload too large")
+
+// payloadLimits are the largest payloads the Invoker will send.
+type payloadLimits struct {
+	sync  int
+	async int
+}
+
+// WithPayloadLimits returns an option which can be passed when initializing
+// an Invoker. If provided the limits passed are used in place of
+// DefaultSyncPayloadLimit and DefaultAsyncPayloadLimit when checking the size
+// of payloads. A limit of 0 or less disables the check for that invocation
+// type.
+func WithPayloadLimits(sync, async int) Option {
+	return func(i *Invoker) {
+		i.payloadLimits = payloadLimits{sync: sync, async: async}
+	}
+}
+
+// payloadLimit returns the largest payload the Invoker will send for an
+// invocation of invocationType, or 0 or less if there's no limit.
+func (i *Invoker) payloadLimit(invocationType string) int {
+	if invocationType == lambda.InvocationTypeEvent {
+		return i.payloadLimits.async
+	}
+	return i.payloadLimits.sync
+}
+
+// checkPayloadSize returns ErrPayloadTooLarge if the payload of input exceeds
+// the limit for its invocation type.
+func (i *Invoker) checkPayloadSize(input *lambda.InvokeInput) error {
+	limit := i.payloadLimit(aws.StringValue(input.InvocationType))
+	if limit <= 0 || len(input.Payload) <= limit {
+		return nil
+	}
+	return fmt.Errorf("%w: %d bytes exceeds the %s limit of %d bytes", ErrPayloadTooLarge, len(input.Payload), aws.StringValue(input.InvocationType), limit)
+}
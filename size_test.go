@@ -0,0 +1,51 @@
+package invoker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokePayloadTooLarge(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	var calls int
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		calls++
+		return &lambda.InvokeOutput{}, nil
+	})
+	body := bytes.Repeat([]byte("a"), DefaultAsyncPayloadLimit+1)
+
+	_, err := New(li, arn).Invoke(ctx, body)
+	require.NoError(t, err)
+
+	_, err = New(li, arn, AsEvent()).Invoke(ctx, body)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrPayloadTooLarge))
+	assert.Contains(t, err.Error(), "262145")
+	assert.Contains(t, err.Error(), "262144")
+	assert.Equal(t, 1, calls)
+}
+
+func TestInvokeWithPayloadLimits(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn, WithPayloadLimits(4, 0))
+	_, err := invoker.Invoke(ctx, []byte(`"ab"`))
+	require.NoError(t, err)
+	_, err = invoker.Invoke(ctx, []byte(`"abc"`))
+	assert.True(t, errors.Is(err, ErrPayloadTooLarge))
+	_, err = invoker.InvokeWith(ctx, []byte(`"abc"`), CallInvocationType(lambda.InvocationTypeEvent))
+	assert.NoError(t, err)
+}
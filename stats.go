@@ -0,0 +1,50 @@
+package invoker
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// Stats counts the invocations made by an Invoker.
+type Stats struct {
+	// Invocations is the number of calls to Invoke, including those which
+	// failed before the lambda function was invoked.
+	Invocations int64
+	// Successes is the number of invocations which returned without error.
+	Successes int64
+	// Failures is the number of invocations which returned an error.
+	Failures int64
+	// InFlight is the number of attempts to invoke the lambda function which
+	// are currently in progress.
+	InFlight int64
+}
+
+// counters hold the Stats of an Invoker; they're updated atomically so it's
+// allocated separately to keep the fields 64-bit aligned.
+type counters struct {
+	invocations int64
+	successes   int64
+	failures    int64
+	inFlight    int64
+}
+
+// record counts an invocation which returned err.
+func (c *counters) record(err error) {
+	atomic.AddInt64(&c.invocations, 1)
+	if err != nil && !errors.Is(err, ErrInvokedAsync) {
+		atomic.AddInt64(&c.failures, 1)
+		return
+	}
+	atomic.AddInt64(&c.successes, 1)
+}
+
+// Stats returns a snapshot of the number of invocations the Invoker has made.
+// Invocations made by WithAsyncFallback are counted as successes.
+func (i *Invoker) Stats() Stats {
+	return Stats{
+		Invocations: atomic.LoadInt64(&i.counters.invocations),
+		Successes:   atomic.LoadInt64(&i.counters.successes),
+		Failures:    atomic.LoadInt64(&i.counters.failures),
+		InFlight:    atomic.LoadInt64(&i.counters.inFlight),
+	}
+}
@@ -0,0 +1,42 @@
+package invoker
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		if string(i.Payload) == `"fail"` {
+			return nil, assert.AnError
+		}
+		return &lambda.InvokeOutput{}, nil
+	})
+	invoker := New(li, arn)
+	var wg sync.WaitGroup
+	for n := 0; n < 10; n++ {
+		body := []byte(`"succeed"`)
+		if n%5 == 0 {
+			body = []byte(`"fail"`)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = invoker.Invoke(ctx, body)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, Stats{
+		Invocations: 10,
+		Successes:   8,
+		Failures:    2,
+	}, invoker.Stats())
+}
@@ -0,0 +1,195 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// ErrStreamingUnsupported is returned by InvokeStream if the LambdaInvoker
+// the Invoker was initialized with doesn't implement LambdaStreamInvoker.
+var ErrStreamingUnsupported = errors.New("lambda invoker doesn't support response streaming")
+
+// LambdaStreamInvoker abstracts the logic of invoking a lambda function with a
+// streamed response behind an interface, this is to allow mocking the aws
+// Lambda implementation.
+type LambdaStreamInvoker interface {
+	InvokeWithResponseStreamWithContext(context.Context, *lambda.InvokeWithResponseStreamInput, ...awsreq.Option) (*lambda.InvokeWithResponseStreamOutput, error)
+}
+
+// InvokeStream invokes the lambda function passing body as the payload, and
+// returns a reader of the response as it's streamed by the function. If the
+// function fails while streaming the reader returns an Error. The reader must
+// be closed. Input mutators, the payload size limit, the rate limit, the
+// bulkhead, the circuit breaker, timeouts, the deadline buffer, Metrics, the
+// Logger and Stats are applied in the same way as Invoke; the timeout and the
+// bulkhead's slot last until the reader is closed. They only observe the
+// response starting to stream, so failures while streaming aren't recorded.
+// Middleware, retries, fallbacks, caching, idempotency, tracing and output
+// mutators (including AsProcedure) aren't applied.
+func (i *Invoker) InvokeStream(ctx context.Context, body json.RawMessage, opts ...awsreq.Option) (io.ReadCloser, error) {
+	if i.err != nil {
+		i.counters.record(i.err)
+		return nil, i.err
+	}
+	li, ok := i.li.(LambdaStreamInvoker)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	r, err := i.invokeStream(ctx, li, body, opts)
+	i.counters.record(err)
+	return r, err
+}
+
+// invokeStream performs the invocation of the lambda function for
+// InvokeStream.
+func (i *Invoker) invokeStream(ctx context.Context, li LambdaStreamInvoker, body json.RawMessage, opts []awsreq.Option) (_ io.ReadCloser, err error) {
+	ctx, cancelBuffer, err := i.reserveDeadline(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var cancelTimeout context.CancelFunc
+	if timeout := i.invocationTimeout(&callConfig{}); timeout > 0 {
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancelTimeout = context.WithCancel(ctx)
+	}
+	cancel := func() {
+		cancelTimeout()
+		cancelBuffer()
+	}
+	release := func() {}
+	defer func() {
+		if err != nil {
+			release()
+			cancel()
+		}
+	}()
+	input := &lambda.InvokeInput{
+		FunctionName:   aws.String(i.arn),
+		InvocationType: aws.String(lambda.InvocationTypeRequestResponse),
+		Payload:        i.normalizePayload(body),
+	}
+	if err := i.mutateInput(ctx, input); err != nil {
+		return nil, err
+	}
+	if err := i.checkPayloadSize(input); err != nil {
+		return nil, err
+	}
+	if !i.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+	release, err = i.ready(ctx)
+	if err != nil {
+		release = func() {}
+		i.breaker.abandon()
+		return nil, err
+	}
+	var requestID string
+	opts = append(i.withRequestOptions(opts), captureRequestID(&requestID))
+	start := time.Now()
+	output, err := li.InvokeWithResponseStreamWithContext(ctx, &lambda.InvokeWithResponseStreamInput{
+		ClientContext:  input.ClientContext,
+		FunctionName:   input.FunctionName,
+		InvocationType: input.InvocationType,
+		LogType:        input.LogType,
+		Payload:        input.Payload,
+		Qualifier:      input.Qualifier,
+	}, opts...)
+	latency := time.Since(start)
+	var started *lambda.InvokeOutput
+	if output != nil {
+		started = &lambda.InvokeOutput{
+			ExecutedVersion: output.ExecutedVersion,
+			StatusCode:      output.StatusCode,
+		}
+	}
+	i.breaker.record(started, err)
+	if requestID == "" {
+		requestID = errorRequestID(err)
+	}
+	if perr := guard(func() error {
+		tags := i.tags(ctx)
+		i.observe(latency, tags, started, err)
+		i.log(1, 0, latency, requestID, tags, input, started, err)
+		return nil
+	}); perr != nil {
+		if err == nil {
+			output.GetStream().Close()
+		}
+		return nil, perr
+	}
+	if err != nil {
+		return nil, err
+	}
+	statusCode := int64(-1)
+	if output.StatusCode != nil {
+		statusCode = *output.StatusCode
+	}
+	return &responseStream{
+		stream:     output.GetStream(),
+		statusCode: statusCode,
+		release: func() {
+			release()
+			cancel()
+		},
+	}, nil
+}
+
+// responseStream reads the payload chunks from a response event stream.
+type responseStream struct {
+	stream     *lambda.InvokeWithResponseStreamEventStream
+	statusCode int64
+	buf        []byte
+	err        error
+	// release releases the resources held for the invocation once the
+	// stream is closed.
+	release   func()
+	closeOnce sync.Once
+}
+
+// Read reads the next chunks of the streamed response into p. Once the
+// stream is complete io.EOF is returned, or an Error if the function failed.
+func (r *responseStream) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		event, ok := <-r.stream.Events()
+		if !ok {
+			r.err = io.EOF
+			if err := r.stream.Err(); err != nil {
+				r.err = err
+			}
+			continue
+		}
+		switch e := event.(type) {
+		case *lambda.InvokeResponseStreamUpdate:
+			r.buf = e.Payload
+		case *lambda.InvokeWithResponseStreamCompleteEvent:
+			if e.ErrorCode != nil {
+				r.err = &Error{
+					error:      errors.New(aws.StringValue(e.ErrorDetails)),
+					StatusCode: r.statusCode,
+					errorType:  *e.ErrorCode,
+				}
+			}
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close closes the underlying event stream, releasing the bulkhead's slot.
+func (r *responseStream) Close() error {
+	r.closeOnce.Do(r.release)
+	return r.stream.Close()
+}
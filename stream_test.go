@@ -0,0 +1,164 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/private/protocol/eventstream"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamEvent returns an event stream message of eventType carrying payload.
+func streamEvent(eventType string, payload []byte) eventstream.Message {
+	return eventstream.Message{
+		Headers: eventstream.Headers{
+			{Name: ":message-type", Value: eventstream.StringValue("event")},
+			{Name: ":event-type", Value: eventstream.StringValue(eventType)},
+		},
+		Payload: payload,
+	}
+}
+
+// newStreamingLambda returns a lambda client whose requests are served by
+// handler, and a func which closes the server.
+func newStreamingLambda(t *testing.T, handler http.HandlerFunc) (*lambda.Lambda, func()) {
+	server := httptest.NewServer(handler)
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:    aws.String(server.URL),
+		Region:      aws.String("eu-west-1"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+		MaxRetries:  aws.Int(0),
+	})
+	require.NoError(t, err)
+	return lambda.New(sess), server.Close
+}
+
+// streamEvents returns a handler which responds with an event stream of
+// events.
+func streamEvents(t *testing.T, events ...eventstream.Message) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		w.WriteHeader(http.StatusOK)
+		encoder := eventstream.NewEncoder(w)
+		for _, event := range events {
+			assert.NoError(t, encoder.Encode(event))
+		}
+	}
+}
+
+func TestInvokeStream(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li, closeServer := newStreamingLambda(t, streamEvents(t,
+		streamEvent("PayloadChunk", []byte(`{"chunk":`)),
+		streamEvent("PayloadChunk", []byte(`"streamed"}`)),
+		streamEvent("InvokeComplete", []byte(`{}`)),
+	))
+	defer closeServer()
+	invoker := New(li, arn)
+	r, err := invoker.InvokeStream(ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+	defer r.Close()
+	result, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, `{"chunk":"streamed"}`, string(result))
+}
+
+func TestInvokeStreamWithError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li, closeServer := newStreamingLambda(t, streamEvents(t,
+		streamEvent("PayloadChunk", []byte(`partial`)),
+		streamEvent("InvokeComplete", []byte(`{"ErrorCode":"Unhandled","ErrorDetails":"boom"}`)),
+	))
+	defer closeServer()
+	invoker := New(li, arn)
+	r, err := invoker.InvokeStream(ctx, nil)
+	require.NoError(t, err)
+	defer r.Close()
+	result, err := io.ReadAll(r)
+	assert.Equal(t, "partial", string(result))
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "boom", e.Error())
+	assert.Equal(t, "Unhandled", e.Type())
+}
+
+func TestInvokeStreamUnsupported(t *testing.T) {
+	t.Parallel()
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	_, err := New(li, "test-arn").InvokeStream(context.Background(), nil)
+	assert.Equal(t, ErrStreamingUnsupported, err)
+}
+
+func TestInvokeStreamInvocationError(t *testing.T) {
+	t.Parallel()
+	li, closeServer := newStreamingLambda(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Amzn-ErrorType", lambda.ErrCodeResourceNotFoundException)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"Type":"User","message":"function not found"}`))
+	})
+	defer closeServer()
+	_, err := New(li, "test-arn").InvokeStream(context.Background(), nil)
+	var aerr awserr.Error
+	require.True(t, errors.As(err, &aerr))
+	assert.Equal(t, lambda.ErrCodeResourceNotFoundException, aerr.Code())
+}
+
+func TestInvokeStreamHoldsSlotUntilClosed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li, closeServer := newStreamingLambda(t, streamEvents(t,
+		streamEvent("PayloadChunk", []byte(`"streamed"`)),
+		streamEvent("InvokeComplete", []byte(`{}`)),
+	))
+	defer closeServer()
+	invoker := New(li, arn, WithMaxConcurrency(1))
+	r, err := invoker.InvokeStream(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), invoker.Stats().InFlight)
+
+	blocked, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = invoker.InvokeStream(blocked, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, r.Close())
+	require.NoError(t, r.Close())
+	assert.Equal(t, int64(0), invoker.Stats().InFlight)
+	r, err = invoker.InvokeStream(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	stats := invoker.Stats()
+	assert.Equal(t, int64(3), stats.Invocations)
+	assert.Equal(t, int64(1), stats.Failures)
+}
+
+func TestInvokeStreamPayloadTooLarge(t *testing.T) {
+	t.Parallel()
+	li, closeServer := newStreamingLambda(t, func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("lambda function shouldn't be invoked")
+	})
+	defer closeServer()
+	invoker := New(li, "test-arn", WithPayloadLimits(8, 8))
+	_, err := invoker.InvokeStream(context.Background(), json.RawMessage(`{"key":"value"}`))
+	assert.ErrorIs(t, err, ErrPayloadTooLarge)
+}
@@ -0,0 +1,64 @@
+package invoker
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracer returns an option which can be passed when initializing an
+// Invoker. If provided, a 'lambda.invoke' span will be started with tracer
+// around every attempt to invoke the lambda function, recording the function
+// name, invocation type and status code along with any error. The span's
+// context is injected into the 'custom' field of the ClientContext as W3C
+// trace context headers, so the lambda function can continue the trace; if
+// the ClientContext can't be extended the function is invoked without them.
+func WithTracer(tracer trace.Tracer) Option {
+	return func(i *Invoker) {
+		i.tracer = tracer
+	}
+}
+
+// startSpan starts a span for an attempt to invoke the lambda function with
+// input, if the Invoker has a tracer, injecting its context into input. If
+// the context can't be injected, for example because the ClientContext isn't
+// valid, a warning is passed to the warning handler and the function is
+// invoked without it. The func returned ends the span, recording the outcome
+// of the attempt.
+func (i *Invoker) startSpan(ctx context.Context, input *lambda.InvokeInput) (context.Context, func(*lambda.InvokeOutput, error)) {
+	if i.tracer == nil {
+		return ctx, func(*lambda.InvokeOutput, error) {}
+	}
+	ctx, span := i.tracer.Start(ctx, "lambda.invoke",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("aws.lambda.function_name", aws.StringValue(input.FunctionName)),
+			attribute.String("aws.lambda.invocation_type", aws.StringValue(input.InvocationType)),
+		),
+	)
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	if len(carrier) > 0 {
+		if err := addClientContextCustom(input, carrier); err != nil {
+			i.warnf("trace context not passed to '%s': %v", i.arn, err)
+		}
+	}
+	return ctx, func(output *lambda.InvokeOutput, err error) {
+		defer span.End()
+		if output != nil && output.StatusCode != nil {
+			span.SetAttributes(attribute.Int64("aws.lambda.status_code", *output.StatusCode))
+		}
+		if err == nil && output != nil && output.FunctionError != nil {
+			err = i.newFunctionError(output)
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+}
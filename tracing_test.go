@@ -0,0 +1,97 @@
+package invoker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInvokeWithTracer(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	var custom map[string]map[string]string
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		require.NotNil(t, i.ClientContext)
+		decoded, err := base64.StdEncoding.DecodeString(*i.ClientContext)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(decoded, &custom))
+		return &lambda.InvokeOutput{
+			StatusCode: aws.Int64(http.StatusOK),
+		}, nil
+	})
+	invoker := New(li, arn, WithTracer(provider.Tracer("test")))
+	_, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "lambda.invoke", span.Name())
+	assert.Contains(t, span.Attributes(), attribute.String("aws.lambda.function_name", arn))
+	assert.Contains(t, span.Attributes(), attribute.String("aws.lambda.invocation_type", lambda.InvocationTypeRequestResponse))
+	assert.Contains(t, span.Attributes(), attribute.Int64("aws.lambda.status_code", http.StatusOK))
+	assert.Contains(t, custom["custom"]["traceparent"], span.SpanContext().TraceID().String())
+}
+
+func TestInvokeWithTracerError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return nil, assert.AnError
+	})
+	invoker := New(li, arn, WithTracer(provider.Tracer("test")))
+	_, err := invoker.Invoke(ctx, nil)
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+}
+
+func TestInvokeWithTracerInvalidClientContext(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.Equal(t, "invalid", aws.StringValue(i.ClientContext))
+		return &lambda.InvokeOutput{
+			StatusCode: aws.Int64(http.StatusOK),
+		}, nil
+	})
+	var warnings []string
+	invoker := New(li, arn, WithTracer(provider.Tracer("test")), AddInputMutator(func(i *lambda.InvokeInput) error {
+		i.ClientContext = aws.String("invalid")
+		return nil
+	}), WithWarningHandler(func(msg string) {
+		warnings = append(warnings, msg)
+	}))
+	_, err := invoker.Invoke(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "trace context not passed to 'test-arn'")
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+}
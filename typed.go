@@ -0,0 +1,69 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/lambda"
+	router "github.com/edstell/lambda-router"
+)
+
+// InvokeTyped marshals req with the Invoker's Codec and invokes the lambda
+// function with it, unmarshaling the result into a Resp. Errors returned by
+// Invoke are returned untouched, along with the zero value of Resp.
+func InvokeTyped[Req, Resp any](ctx context.Context, inv *Invoker, req Req) (Resp, error) {
+	var rsp Resp
+	body, err := inv.codec.Marshal(req)
+	if err != nil {
+		return rsp, err
+	}
+	result, err := inv.Invoke(ctx, body)
+	if err != nil {
+		return rsp, err
+	}
+	if len(result) == 0 {
+		return rsp, nil
+	}
+	if err := inv.codec.Unmarshal(result, &rsp); err != nil {
+		var zero Resp
+		return zero, err
+	}
+	return rsp, nil
+}
+
+// AsProcedureTyped returns an option which can be passed when initializing an
+// Invoker. It configures invocation in the same way as AsProcedure, but the
+// body of each invocation must decode into a Req, and errors returned by the
+// procedure are unmarshaled into a new Err; *Err must implement error. Bodies
+// which don't decode into a Req are rejected without invoking the function;
+// empty bodies are replaced by the empty payload before they're decoded. Call
+// the Invoker with InvokeTyped, using the same Req, to have requests checked
+// at compile time. WithErrorPrototype takes precedence over Err if it's also
+// provided.
+func AsProcedureTyped[Req, Err any, PErr interface {
+	*Err
+	error
+}](procedure string) Option {
+	return func(i *Invoker) {
+		i.inputMutators = append(i.inputMutators, func(_ context.Context, input *lambda.InvokeInput) error {
+			var req Req
+			if err := i.codec.Unmarshal(input.Payload, &req); err != nil {
+				return fmt.Errorf("procedure '%s' request: %w", procedure, err)
+			}
+			return nil
+		})
+		asProcedure(procedure, func(body json.RawMessage) router.Request {
+			return router.Request{
+				Procedure: procedure,
+				Body:      body,
+			}
+		}, func(raw json.RawMessage) error {
+			var perr PErr = new(Err)
+			if err := i.codec.Unmarshal(raw, perr); err != nil {
+				return fmt.Errorf("unmarshaling procedure error: %w", err)
+			}
+			return perr
+		})(i)
+	}
+}
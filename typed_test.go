@@ -0,0 +1,157 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvokeTyped(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	type Request struct {
+		Key string `json:"key"`
+	}
+	type Response struct {
+		Result string `json:"result"`
+	}
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		req := Request{}
+		if err := json.Unmarshal(i.Payload, &req); err != nil {
+			return nil, err
+		}
+		bytes, err := json.Marshal(Response{Result: req.Key})
+		if err != nil {
+			return nil, err
+		}
+		return &lambda.InvokeOutput{
+			Payload: bytes,
+		}, nil
+	})
+	invoker := New(li, arn)
+	rsp, err := InvokeTyped[Request, Response](ctx, invoker, Request{Key: "value"})
+	require.NoError(t, err)
+	assert.Equal(t, Response{Result: "value"}, rsp)
+}
+
+func TestInvokeTypedWithError(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("invocation failed"),
+			StatusCode:    aws.Int64(http.StatusBadRequest),
+		}, nil
+	})
+	invoker := New(li, arn)
+	rsp, err := InvokeTyped[struct{}, map[string]string](ctx, invoker, struct{}{})
+	require.Error(t, err)
+	assert.Nil(t, rsp)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, int64(http.StatusBadRequest), e.StatusCode)
+}
+
+type procedureError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *procedureError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func TestInvokeAsProcedureTyped(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	type Request struct {
+		Key string `json:"key"`
+	}
+	li := LambdaInvokerFunc(func(_ context.Context, i *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		req := struct {
+			Procedure string  `json:"procedure"`
+			Body      Request `json:"body"`
+		}{}
+		require.NoError(t, json.Unmarshal(i.Payload, &req))
+		assert.Equal(t, "Do", req.Procedure)
+		if req.Body.Key == "fail" {
+			return &lambda.InvokeOutput{
+				Payload: []byte(`{"error":{"code":"invalid","message":"bad key"}}`),
+			}, nil
+		}
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"body":"result"}`),
+		}, nil
+	})
+	invoker := New(li, arn, AsProcedureTyped[Request, procedureError]("Do"))
+	result, err := invoker.Invoke(ctx, json.RawMessage(`{"key":"value"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `"result"`, string(result))
+
+	_, err = invoker.Invoke(ctx, json.RawMessage(`{"key":"fail"}`))
+	perr := &procedureError{}
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, "invalid", perr.Code)
+	assert.Equal(t, "bad key", perr.Message)
+}
+
+func TestInvokeAsProcedureTypedInvalidRequest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	type Request struct {
+		Key string `json:"key"`
+	}
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Fatal("lambda function shouldn't be invoked")
+		return nil, nil
+	})
+	_, err := New(li, arn, AsProcedureTyped[Request, procedureError]("Do")).Invoke(ctx, json.RawMessage(`{"key":1}`))
+	assert.Error(t, err)
+}
+
+func TestInvokeTypedAsProcedureTyped(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	type Request struct {
+		Key string `json:"key"`
+	}
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{
+			Payload: []byte(`{"body":"result"}`),
+		}, nil
+	})
+	codec := &countingCodec{}
+	invoker := New(li, arn, AsProcedureTyped[Request, procedureError]("Do"), WithCodec(codec))
+	result, err := InvokeTyped[Request, string](ctx, invoker, Request{Key: "value"})
+	require.NoError(t, err)
+	assert.Equal(t, "result", result)
+	assert.Equal(t, 2, codec.marshals)
+	assert.Equal(t, 3, codec.unmarshals)
+}
+
+func TestHealthcheckAsProcedureTyped(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	type Request struct {
+		Key string `json:"key"`
+	}
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	err := New(li, arn, AsProcedureTyped[Request, procedureError]("Do")).Healthcheck(ctx)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,92 @@
+package invoker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// ErrVerifyUnsupported is returned by Verify if the LambdaInvoker the Invoker
+// was initialized with doesn't implement LambdaConfigurationGetter.
+var ErrVerifyUnsupported = errors.New("lambda invoker doesn't support getting function configuration")
+
+// LambdaConfigurationGetter abstracts the logic of getting the configuration
+// of a lambda function behind an interface, this is to allow mocking the aws
+// Lambda implementation.
+type LambdaConfigurationGetter interface {
+	GetFunctionConfigurationWithContext(context.Context, *lambda.GetFunctionConfigurationInput, ...awsreq.Option) (*lambda.FunctionConfiguration, error)
+}
+
+// FunctionExpectations describe how the lambda function is expected to be
+// configured; zero values aren't checked.
+type FunctionExpectations struct {
+	// Runtime is the runtime the function must use, e.g. 'provided.al2'.
+	Runtime string
+	// MinTimeout is the shortest timeout the function may be configured with.
+	MinTimeout time.Duration
+	// MinMemorySize is the least memory, in MB, the function may be
+	// configured with.
+	MinMemorySize int64
+}
+
+// ConfigurationError is returned by Verify if the lambda function isn't
+// configured as expected.
+type ConfigurationError struct {
+	// Mismatches describe each way the configuration didn't match.
+	Mismatches []string
+}
+
+// Error lists the mismatches.
+func (e *ConfigurationError) Error() string {
+	return "function configuration doesn't match expectations: " + strings.Join(e.Mismatches, "; ")
+}
+
+// Verify gets the configuration of the lambda function and checks it matches
+// expect, returning a ConfigurationError describing any mismatches. Input
+// mutators are applied so the same version and account are checked as by
+// Invoke. It's intended to be called at startup, to catch misconfiguration
+// before the function is invoked.
+func (i *Invoker) Verify(ctx context.Context, expect FunctionExpectations) error {
+	if i.err != nil {
+		return i.err
+	}
+	li, ok := i.li.(LambdaConfigurationGetter)
+	if !ok {
+		return ErrVerifyUnsupported
+	}
+	input := &lambda.InvokeInput{
+		FunctionName:   aws.String(i.arn),
+		InvocationType: aws.String(i.invocationType),
+		Payload:        i.normalizePayload(nil),
+	}
+	if err := i.mutateInput(ctx, input); err != nil {
+		return err
+	}
+	config, err := li.GetFunctionConfigurationWithContext(ctx, &lambda.GetFunctionConfigurationInput{
+		FunctionName: input.FunctionName,
+		Qualifier:    input.Qualifier,
+	}, i.requestOptions...)
+	if err != nil {
+		return newInvocationError(err)
+	}
+	var mismatches []string
+	if runtime := aws.StringValue(config.Runtime); expect.Runtime != "" && runtime != expect.Runtime {
+		mismatches = append(mismatches, fmt.Sprintf("runtime is '%s', expected '%s'", runtime, expect.Runtime))
+	}
+	if timeout := time.Duration(aws.Int64Value(config.Timeout)) * time.Second; timeout < expect.MinTimeout {
+		mismatches = append(mismatches, fmt.Sprintf("timeout is %s, expected at least %s", timeout, expect.MinTimeout))
+	}
+	if memory := aws.Int64Value(config.MemorySize); memory < expect.MinMemorySize {
+		mismatches = append(mismatches, fmt.Sprintf("memory size is %dMB, expected at least %dMB", memory, expect.MinMemorySize))
+	}
+	if len(mismatches) > 0 {
+		return &ConfigurationError{Mismatches: mismatches}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package invoker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type configurationInvoker struct {
+	LambdaInvokerFunc
+	config *lambda.FunctionConfiguration
+	input  *lambda.GetFunctionConfigurationInput
+}
+
+func (c *configurationInvoker) GetFunctionConfigurationWithContext(_ context.Context, input *lambda.GetFunctionConfigurationInput, _ ...awsreq.Option) (*lambda.FunctionConfiguration, error) {
+	c.input = input
+	return c.config, nil
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := &configurationInvoker{
+		config: &lambda.FunctionConfiguration{
+			Runtime:    aws.String("provided.al2"),
+			Timeout:    aws.Int64(30),
+			MemorySize: aws.Int64(512),
+		},
+	}
+	invoker := New(li, arn, WithQualifier("prod"))
+	err := invoker.Verify(ctx, FunctionExpectations{
+		Runtime:       "provided.al2",
+		MinTimeout:    30 * time.Second,
+		MinMemorySize: 256,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, arn, *li.input.FunctionName)
+	assert.Equal(t, "prod", *li.input.Qualifier)
+
+	err = invoker.Verify(ctx, FunctionExpectations{
+		Runtime:       "go1.x",
+		MinTimeout:    time.Minute,
+		MinMemorySize: 1024,
+	})
+	var e *ConfigurationError
+	require.True(t, errors.As(err, &e))
+	assert.Len(t, e.Mismatches, 3)
+	assert.Contains(t, err.Error(), "timeout is 30s, expected at least 1m0s")
+}
+
+func TestVerifyUnsupported(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return &lambda.InvokeOutput{}, nil
+	})
+	err := New(li, arn).Verify(ctx, FunctionExpectations{})
+	assert.Equal(t, ErrVerifyUnsupported, err)
+}
@@ -0,0 +1,99 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// WarmupPayload is the body the lambda function is invoked with by Warm; the
+// function should detect it and return without doing any work.
+var WarmupPayload = json.RawMessage(`{"warmup":true}`)
+
+// Warm starts a goroutine which invokes the lambda function with
+// WarmupPayload immediately, then every interval, to keep an instance of it
+// warm and avoid cold starts on latency sensitive paths. Each ping must
+// complete within interval. If the Invoker was initialized with AsProcedure
+// the payload is sent as the procedure's body. Each ping is a single attempt
+// which bypasses Middleware, the cache, the idempotency store, the circuit
+// breaker and Stats, so it always reaches the function. Failed pings are
+// logged and measured by the Logger and Metrics the Invoker was initialized
+// with, like any other invocation, and passed to onError if it isn't nil;
+// they don't stop the pings. Calling stop stops the goroutine, cancelling any
+// ping in flight, and waits for it to return; it's safe to call more than
+// once. If interval isn't positive no goroutine is started, an error is
+// passed to onError, and stop does nothing.
+func (i *Invoker) Warm(interval time.Duration, onError func(error)) (stop func()) {
+	if interval <= 0 {
+		if onError != nil {
+			onError(errors.New("warm interval must be positive"))
+		}
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			i.ping(ctx, interval, onError)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(cancel)
+		<-done
+	}
+}
+
+// ping invokes the lambda function with WarmupPayload, passing any error to
+// onError unless ctx was cancelled.
+func (i *Invoker) ping(ctx context.Context, timeout time.Duration, onError func(error)) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := i.warmup(ctx)
+	if err != nil && onError != nil && ctx.Err() != context.Canceled {
+		onError(err)
+	}
+}
+
+// warmup makes a single attempt to invoke the lambda function with
+// WarmupPayload. It bypasses Middleware, the cache, the idempotency store, the
+// circuit breaker and Stats, so every ping reaches the function and pings
+// don't affect how other invocations are handled.
+func (i *Invoker) warmup(ctx context.Context) error {
+	if i.err != nil {
+		return i.err
+	}
+	input := &lambda.InvokeInput{
+		FunctionName:   aws.String(i.arn),
+		InvocationType: aws.String(i.invocationType),
+		Payload:        i.normalizePayload(WarmupPayload),
+	}
+	if err := i.mutateInput(ctx, input); err != nil {
+		return err
+	}
+	var hookErr error
+	output, err := i.attempt(ctx, 1, 0, input, nil, &hookErr)
+	if err != nil {
+		return newInvocationError(err)
+	}
+	if hookErr != nil {
+		return hookErr
+	}
+	if output.FunctionError != nil {
+		return i.newFunctionError(output)
+	}
+	return nil
+}
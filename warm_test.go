@@ -0,0 +1,119 @@
+package invoker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsreq "github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarm(t *testing.T) {
+	t.Parallel()
+	arn := "test-arn"
+	var mu sync.Mutex
+	pings := 0
+	li := LambdaInvokerFunc(func(_ context.Context, input *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		assert.JSONEq(t, `{"warmup":true}`, string(input.Payload))
+		mu.Lock()
+		defer mu.Unlock()
+		pings++
+		return &lambda.InvokeOutput{}, nil
+	})
+	stop := New(li, arn).Warm(time.Millisecond, func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	})
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return pings >= 3
+	}, time.Second, time.Millisecond)
+	stop()
+	stop()
+	mu.Lock()
+	stopped := pings
+	mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, stopped, pings)
+}
+
+func TestWarmError(t *testing.T) {
+	t.Parallel()
+	arn := "test-arn"
+	failure := errors.New("unavailable")
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		return nil, failure
+	})
+	errs := make(chan error, 1)
+	stop := New(li, arn).Warm(time.Hour, func(err error) {
+		errs <- err
+	})
+	defer stop()
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, failure)
+	case <-time.After(time.Second):
+		t.Fatal("error wasn't reported")
+	}
+}
+
+func TestWarmInvalidInterval(t *testing.T) {
+	t.Parallel()
+	arn := "test-arn"
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		t.Error("lambda function shouldn't be invoked")
+		return &lambda.InvokeOutput{}, nil
+	})
+	var errs []error
+	stop := New(li, arn).Warm(0, func(err error) {
+		errs = append(errs, err)
+	})
+	stop()
+	assert.Len(t, errs, 1)
+	New(li, arn).Warm(-time.Second, nil)()
+}
+
+func TestWarmBypassesCacheAndBreaker(t *testing.T) {
+	t.Parallel()
+	arn := "test-arn"
+	var mu sync.Mutex
+	pings := 0
+	li := LambdaInvokerFunc(func(_ context.Context, _ *lambda.InvokeInput, _ ...awsreq.Option) (*lambda.InvokeOutput, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		pings++
+		return &lambda.InvokeOutput{
+			FunctionError: aws.String("Unhandled"),
+			Payload:       []byte(`{"errorMessage":"cold"}`),
+		}, nil
+	})
+	invoker := New(li, arn,
+		WithCache(NewMemoryCache(), time.Hour),
+		WithIdempotency(func(json.RawMessage) string {
+			return "key"
+		}, NewMemoryCache(), time.Hour),
+		WithCircuitBreaker(BreakerConfig{
+			Threshold: 1,
+			Cooldown:  time.Hour,
+		}),
+	)
+	stop := invoker.Warm(time.Millisecond, nil)
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return pings >= 3
+	}, time.Second, time.Millisecond)
+	stop()
+	assert.Equal(t, int64(0), invoker.Stats().Invocations)
+	_, err := invoker.Invoke(context.Background(), nil)
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+}